@@ -1,15 +1,515 @@
 package ast
 
 import (
-	"github.com/riyanshkarani011235/meme/lexer"
+	"strconv"
+	"strings"
+
 	"github.com/riyanshkarani011235/meme/token"
 )
 
+// Node is implemented by every node of the tree. Pos and
+// End are positions in the FileSet the file was parsed with.
 type Node interface {
-	Token() *token.Token
+	Pos() token.Pos // position of the first character of the node
+	End() token.Pos // position just past the last character of the node
 }
 
 type Statement interface {
 	Node
 	statementNode()
 }
+
+// TypeExpr is implemented by every node that can appear
+// in a type position, e.g. the type of a field or a type
+// argument of a generic instantiation.
+type TypeExpr interface {
+	Node
+	typeExprNode()
+}
+
+// Decl is implemented by the declarations at the top level
+// of a file: concepts, enums and relations.
+type Decl interface {
+	Statement
+	declNode()
+}
+
+// Expr is implemented by every node that can appear where
+// a value is expected, e.g. the default value of a field.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// ----------
+// File level
+// ----------
+
+// File is the root of the tree produced for a single
+// meme source file.
+type File struct {
+	Name      string         // the name of the source file, if known
+	EOF       token.Pos      // the end of the file
+	Package   *PackageClause // nil if the file is not part of a named package
+	Imports   []*ImportDecl
+	Decls     []Decl          // the declarations of the file, in source order
+	Concepts  []*ConceptDecl  // the concepts of Decls
+	Enums     []*EnumDecl     // the enums of Decls
+	Relations []*RelationDecl // the relations of Decls
+}
+
+// PackageName returns the name of the package of the file,
+// or "" if it does not declare one.
+func (f *File) PackageName() string {
+	if f.Package == nil {
+		return ""
+	}
+	return f.Package.Name.Name
+}
+
+func (f *File) Pos() token.Pos {
+	if f.Package != nil {
+		return f.Package.Pos()
+	}
+	if len(f.Imports) > 0 {
+		return f.Imports[0].Pos()
+	}
+
+	if len(f.Decls) > 0 {
+		return f.Decls[0].Pos()
+	}
+	return f.EOF
+}
+
+func (f *File) End() token.Pos { return f.EOF }
+
+// ------------
+// Declarations
+// ------------
+
+// PackageClause represents
+//
+//	package name
+type PackageClause struct {
+	Package token.Pos // position of the "package" keyword
+	Name    *Ident
+}
+
+func (d *PackageClause) Pos() token.Pos { return d.Package }
+func (d *PackageClause) End() token.Pos { return d.Name.End() }
+func (d *PackageClause) statementNode() {}
+
+// ImportDecl represents
+//
+//	import Name "path"
+//
+// where path is a .meme file or a directory of .meme files,
+// relative to the directory of the importing file, or to the
+// directory of the builtin library if it starts with
+// builtin/, as in "builtin/time.meme"; see loader.Target.
+// The optional Name is an alias for the package of the
+// imported files.
+type ImportDecl struct {
+	Import token.Pos // position of the "import" keyword
+	Name   *Ident    // nil if there is no alias
+	Path   *BasicLit // a string literal
+}
+
+func (d *ImportDecl) Pos() token.Pos { return d.Import }
+func (d *ImportDecl) End() token.Pos { return d.Path.End() }
+func (d *ImportDecl) statementNode() {}
+
+// Value returns the imported path without quotes.
+func (d *ImportDecl) Value() string {
+	path, err := strconv.Unquote(d.Path.Value)
+	if err != nil {
+		// the lexer only produces well formed literals
+		return d.Path.Value
+	}
+	return path
+}
+
+// ConceptDecl represents
+//
+//	@annotations... concept Name<T, ...> extends Base<...> { fields... }
+type ConceptDecl struct {
+	Doc         *CommentGroup // nil if there is no doc comment
+	Annotations []*Annotation
+	Concept     token.Pos // position of the "concept" keyword
+	Name        *Ident
+	TypeParams  []*Ident   // nil if the concept is not generic
+	Extends     *NamedType // nil if there is no extends clause
+	Fields      []*FieldDecl
+	Rbrace      token.Pos // position of the closing "}"
+}
+
+func (d *ConceptDecl) Pos() token.Pos {
+	if len(d.Annotations) > 0 {
+		return d.Annotations[0].Pos()
+	}
+	return d.Concept
+}
+
+func (d *ConceptDecl) End() token.Pos { return d.Rbrace + 1 }
+func (d *ConceptDecl) statementNode() {}
+func (d *ConceptDecl) declNode()      {}
+
+// FieldDecl represents
+//
+//	@annotations... required|optional name Type = Default
+type FieldDecl struct {
+	Doc         *CommentGroup // nil if there is no doc comment
+	Annotations []*Annotation
+	Modifier    token.Pos // position of the "required" or "optional" keyword
+	Required    bool
+	Name        *Ident
+	Type        TypeExpr
+	Default     Expr // nil if there is no default value
+}
+
+func (d *FieldDecl) Pos() token.Pos {
+	if len(d.Annotations) > 0 {
+		return d.Annotations[0].Pos()
+	}
+	return d.Modifier
+}
+
+func (d *FieldDecl) End() token.Pos {
+	if d.Default != nil {
+		return d.Default.End()
+	}
+	return d.Type.End()
+}
+
+func (d *FieldDecl) statementNode() {}
+
+// EnumDecl represents
+//
+//	@annotations... enum Name { Member, Member = Value, ... }
+type EnumDecl struct {
+	Doc         *CommentGroup // nil if there is no doc comment
+	Annotations []*Annotation
+	Enum        token.Pos // position of the "enum" keyword
+	Name        *Ident
+	Members     []*EnumMember
+	Rbrace      token.Pos // position of the closing "}"
+}
+
+func (d *EnumDecl) Pos() token.Pos {
+	if len(d.Annotations) > 0 {
+		return d.Annotations[0].Pos()
+	}
+	return d.Enum
+}
+
+func (d *EnumDecl) End() token.Pos { return d.Rbrace + 1 }
+func (d *EnumDecl) statementNode() {}
+func (d *EnumDecl) declNode()      {}
+
+// RelationDecl represents
+//
+//	@annotations... relation Name from one|many A to one|many B properties... { fields... }
+//
+// where the cardinalities are optional.
+type RelationDecl struct {
+	Doc         *CommentGroup // nil if there is no doc comment
+	Annotations []*Annotation
+	Relation    token.Pos // position of the "relation" keyword
+	Name        *Ident
+	From        *RelationEnd
+	To          *RelationEnd
+	Properties  []*RelationProperty
+	Fields      []*FieldDecl
+	Rbrace      token.Pos // position of the closing "}"
+}
+
+func (d *RelationDecl) Pos() token.Pos {
+	if len(d.Annotations) > 0 {
+		return d.Annotations[0].Pos()
+	}
+	return d.Relation
+}
+
+func (d *RelationDecl) End() token.Pos { return d.Rbrace + 1 }
+func (d *RelationDecl) statementNode() {}
+func (d *RelationDecl) declNode()      {}
+
+// RelationEnd is one of the ends of a relation, such as
+// "from many Issue". The words from, to, one and many are
+// only keywords in this position.
+type RelationEnd struct {
+	Keyword     *Ident // "from" or "to"
+	Cardinality *Ident // "one" or "many", nil if it is not given
+	Type        *NamedType
+}
+
+func (e *RelationEnd) Pos() token.Pos { return e.Keyword.Pos() }
+func (e *RelationEnd) End() token.Pos { return e.Type.End() }
+
+// RelationProperty is a property of a relation: symmetric,
+// transitive, acyclic or "inverse of Relation". These words
+// are only keywords in this position.
+type RelationProperty struct {
+	Name *Ident
+	Of   *NamedType // the relation this one is the inverse of, nil for other properties
+}
+
+func (p *RelationProperty) Pos() token.Pos { return p.Name.Pos() }
+
+func (p *RelationProperty) End() token.Pos {
+	if p.Of != nil {
+		return p.Of.End()
+	}
+	return p.Name.End()
+}
+
+// EnumMember is a member of an enum, with the comments
+// written on the lines right above it.
+type EnumMember struct {
+	Doc   *CommentGroup // nil if there is no doc comment
+	Name  *Ident
+	Value Expr // nil if there is no value
+}
+
+func (m *EnumMember) Pos() token.Pos { return m.Name.Pos() }
+
+func (m *EnumMember) End() token.Pos {
+	if m.Value != nil {
+		return m.Value.End()
+	}
+	return m.Name.End()
+}
+
+// Annotation represents
+//
+//	@Name or @Name(Args, ...)
+//
+// on a concept or a field. Any name is accepted, the built-in
+// annotations are checked by the resolver.
+type Annotation struct {
+	At     token.Pos // position of the "@"
+	Name   *Ident
+	Lparen token.Pos // NoPos if there is no argument list
+	Args   []Expr    // nil if there are no arguments
+	Rparen token.Pos // NoPos if there is no argument list
+}
+
+func (a *Annotation) Pos() token.Pos { return a.At }
+
+func (a *Annotation) End() token.Pos {
+	if a.Rparen.IsValid() {
+		return a.Rparen + 1
+	}
+	return a.Name.End()
+}
+
+// Ident is a name, e.g. the name of a concept, a field
+// or a type parameter. Used as a value, it names a member
+// of an enum.
+type Ident struct {
+	NamePos token.Pos
+	Name    string
+}
+
+func (i *Ident) Pos() token.Pos { return i.NamePos }
+func (i *Ident) End() token.Pos { return i.NamePos + token.Pos(len(i.Name)) }
+func (i *Ident) exprNode()      {}
+
+// --------
+// Comments
+// --------
+
+// Comment is a single // or /* */ comment. Text is the
+// comment as written, with its markers.
+type Comment struct {
+	Slash token.Pos // position of the "/" starting the comment
+	Text  string
+}
+
+func (c *Comment) Pos() token.Pos { return c.Slash }
+func (c *Comment) End() token.Pos { return c.Slash + token.Pos(len(c.Text)) }
+
+// CommentGroup is a sequence of comments on successive
+// lines, with no other token in between. The group ending on
+// the line right above a declaration, a field or an enum
+// member, or above their first annotation, is their doc
+// comment.
+type CommentGroup struct {
+	List []*Comment
+}
+
+func (g *CommentGroup) Pos() token.Pos { return g.List[0].Pos() }
+func (g *CommentGroup) End() token.Pos { return g.List[len(g.List)-1].End() }
+
+// Text returns the text of the comments without their
+// markers ("//", "///", "/*" and "*/"), leading spaces and
+// leading "*" of block comment lines, one line of text per
+// line of comment. Empty lines at the start and at the end
+// are removed.
+func (g *CommentGroup) Text() string {
+	if g == nil {
+		return ""
+	}
+
+	lines := make([]string, 0)
+	for _, c := range g.List {
+		text := c.Text
+		if strings.HasPrefix(text, "//") {
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(text[2:], "/")))
+			continue
+		}
+
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "*") {
+				line = strings.TrimSpace(line[1:])
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ----------------
+// Type expressions
+// ----------------
+
+// PrimitiveType is one of the basic types: integer,
+// string or boolean. Kind holds the keyword's token type.
+type PrimitiveType struct {
+	TypePos token.Pos
+	Kind    token.TokenType
+	Literal string // the keyword as written
+}
+
+func (t *PrimitiveType) Pos() token.Pos { return t.TypePos }
+func (t *PrimitiveType) End() token.Pos { return t.TypePos + token.Pos(len(t.Literal)) }
+func (t *PrimitiveType) typeExprNode()  {}
+
+// NamedType is a reference to a concept, an enum or a type
+// parameter, optionally qualified by a package as in
+// scrum.Issue and instantiated with type arguments as in
+// TypedList<TodoListItem>.
+type NamedType struct {
+	Package *Ident // the package or import alias, nil if the name is not qualified
+	Name    *Ident
+	Args    []TypeExpr // nil if there are no type arguments
+	Rangle  token.Pos  // position of the closing ">", NoPos if there are no type arguments
+}
+
+func (t *NamedType) Pos() token.Pos {
+	if t.Package != nil {
+		return t.Package.Pos()
+	}
+	return t.Name.Pos()
+}
+
+// QualifiedName returns the name as written, with its
+// package if there is one.
+func (t *NamedType) QualifiedName() string {
+	if t.Package != nil {
+		return t.Package.Name + "." + t.Name.Name
+	}
+	return t.Name.Name
+}
+
+func (t *NamedType) End() token.Pos {
+	if t.Rangle.IsValid() {
+		return t.Rangle + 1
+	}
+	return t.Name.End()
+}
+
+func (t *NamedType) typeExprNode() {}
+
+// ListType represents [Elem].
+type ListType struct {
+	Lbrack token.Pos
+	Elem   TypeExpr
+	Rbrack token.Pos
+}
+
+func (t *ListType) Pos() token.Pos { return t.Lbrack }
+func (t *ListType) End() token.Pos { return t.Rbrack + 1 }
+func (t *ListType) typeExprNode()  {}
+
+// TupleType represents (A, B, ...).
+type TupleType struct {
+	Lparen token.Pos
+	Elems  []TypeExpr
+	Rparen token.Pos
+}
+
+func (t *TupleType) Pos() token.Pos { return t.Lparen }
+func (t *TupleType) End() token.Pos { return t.Rparen + 1 }
+func (t *TupleType) typeExprNode()  {}
+
+// OneOfType represents oneof(A, B, ...).
+type OneOfType struct {
+	OneOf   token.Pos // position of the "oneof" keyword
+	Options []TypeExpr
+	Rparen  token.Pos
+}
+
+func (t *OneOfType) Pos() token.Pos { return t.OneOf }
+func (t *OneOfType) End() token.Pos { return t.Rparen + 1 }
+func (t *OneOfType) typeExprNode()  {}
+
+// AnyOfType represents anyof(A, B, ...).
+type AnyOfType struct {
+	AnyOf   token.Pos // position of the "anyof" keyword
+	Options []TypeExpr
+	Rparen  token.Pos
+}
+
+func (t *AnyOfType) Pos() token.Pos { return t.AnyOf }
+func (t *AnyOfType) End() token.Pos { return t.Rparen + 1 }
+func (t *AnyOfType) typeExprNode()  {}
+
+// -----------
+// Expressions
+// -----------
+
+// BasicLit is a literal of a basic type. Kind is one of
+// TokenIntegerLiteral, TokenFloatLiteral, TokenStringLiteral,
+// TokenTrue or TokenFalse, and Value is the literal as
+// written, e.g. 0x2A or "a\tb" with its quotes.
+type BasicLit struct {
+	ValuePos token.Pos
+	Kind     token.TokenType
+	Value    string
+}
+
+func (e *BasicLit) Pos() token.Pos { return e.ValuePos }
+func (e *BasicLit) End() token.Pos { return e.ValuePos + token.Pos(len(e.Value)) }
+func (e *BasicLit) exprNode()      {}
+
+// ListLit represents [A, B, ...].
+type ListLit struct {
+	Lbrack token.Pos
+	Elems  []Expr // nil for []
+	Rbrack token.Pos
+}
+
+func (e *ListLit) Pos() token.Pos { return e.Lbrack }
+func (e *ListLit) End() token.Pos { return e.Rbrack + 1 }
+func (e *ListLit) exprNode()      {}
+
+// TupleLit represents (A, B, ...).
+type TupleLit struct {
+	Lparen token.Pos
+	Elems  []Expr
+	Rparen token.Pos
+}
+
+func (e *TupleLit) Pos() token.Pos { return e.Lparen }
+func (e *TupleLit) End() token.Pos { return e.Rparen + 1 }
+func (e *TupleLit) exprNode()      {}
@@ -0,0 +1,196 @@
+package ast
+
+import (
+	"fmt"
+)
+
+// A Visitor's Visit method is invoked for each node
+// encountered by Walk. If the result visitor w is not nil,
+// Walk visits each of the children of node with the visitor
+// w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a tree in depth-first order. It starts by
+// calling v.Visit(node); node must not be nil. The children
+// of a node are visited in source order, and so are the
+// declarations of a file, whatever their kind.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *File:
+		if n.Package != nil {
+			Walk(v, n.Package)
+		}
+		for _, d := range n.Imports {
+			Walk(v, d)
+		}
+		for _, d := range n.Decls {
+			Walk(v, d)
+		}
+
+	case *ConceptDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Name)
+		for _, param := range n.TypeParams {
+			Walk(v, param)
+		}
+		if n.Extends != nil {
+			Walk(v, n.Extends)
+		}
+		for _, f := range n.Fields {
+			Walk(v, f)
+		}
+
+	case *FieldDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Name)
+		Walk(v, n.Type)
+		if n.Default != nil {
+			Walk(v, n.Default)
+		}
+
+	case *PackageClause:
+		Walk(v, n.Name)
+
+	case *ImportDecl:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		Walk(v, n.Path)
+
+	case *EnumDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Name)
+		for _, m := range n.Members {
+			Walk(v, m)
+		}
+
+	case *RelationDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		walkAnnotations(v, n.Annotations)
+		Walk(v, n.Name)
+		Walk(v, n.From)
+		Walk(v, n.To)
+		for _, p := range n.Properties {
+			Walk(v, p)
+		}
+		for _, f := range n.Fields {
+			Walk(v, f)
+		}
+
+	case *RelationEnd:
+		Walk(v, n.Keyword)
+		if n.Cardinality != nil {
+			Walk(v, n.Cardinality)
+		}
+		Walk(v, n.Type)
+
+	case *RelationProperty:
+		Walk(v, n.Name)
+		if n.Of != nil {
+			Walk(v, n.Of)
+		}
+
+	case *EnumMember:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		Walk(v, n.Name)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *CommentGroup:
+		for _, c := range n.List {
+			Walk(v, c)
+		}
+
+	case *Annotation:
+		Walk(v, n.Name)
+		walkExprList(v, n.Args)
+
+	case *NamedType:
+		if n.Package != nil {
+			Walk(v, n.Package)
+		}
+		Walk(v, n.Name)
+		walkTypeList(v, n.Args)
+
+	case *ListType:
+		Walk(v, n.Elem)
+
+	case *TupleType:
+		walkTypeList(v, n.Elems)
+
+	case *OneOfType:
+		walkTypeList(v, n.Options)
+
+	case *AnyOfType:
+		walkTypeList(v, n.Options)
+
+	case *ListLit:
+		walkExprList(v, n.Elems)
+
+	case *TupleLit:
+		walkExprList(v, n.Elems)
+
+	case *Ident, *PrimitiveType, *BasicLit, *Comment:
+		// nothing to do
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkAnnotations(v Visitor, list []*Annotation) {
+	for _, a := range list {
+		Walk(v, a)
+	}
+}
+
+func walkTypeList(v Visitor, list []TypeExpr) {
+	for _, t := range list {
+		Walk(v, t)
+	}
+}
+
+func walkExprList(v Visitor, list []Expr) {
+	for _, e := range list {
+		Walk(v, e)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a tree in depth-first order. It starts
+// by calling f(node); node must not be nil. If f returns
+// true, Inspect invokes f recursively for each of the
+// children of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
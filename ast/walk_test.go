@@ -0,0 +1,135 @@
+package ast
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+func ident(name string) *Ident {
+	return &Ident{Name: name}
+}
+
+//	concept TodoList<T> extends TypedList<T> {
+//	    optional items [oneof(T, string)] = ["a"]
+//	    @deprecated("old") optional pair (T, T)
+//	}
+func testFile() *File {
+	f := &File{
+		Concepts: []*ConceptDecl{{
+			Name:       ident("TodoList"),
+			TypeParams: []*Ident{ident("T")},
+			Extends: &NamedType{
+				Name: ident("TypedList"),
+				Args: []TypeExpr{&NamedType{Name: ident("T")}},
+			},
+			Fields: []*FieldDecl{
+				{
+					Name: ident("items"),
+					Type: &ListType{Elem: &OneOfType{Options: []TypeExpr{
+						&NamedType{Name: ident("T")},
+						&PrimitiveType{Kind: token.TokenStringType, Literal: "string"},
+					}}},
+					Default: &ListLit{Elems: []Expr{
+						&BasicLit{Kind: token.TokenStringLiteral, Value: `"a"`},
+					}},
+				},
+				{
+					Annotations: []*Annotation{{
+						Name: ident("deprecated"),
+						Args: []Expr{&BasicLit{Kind: token.TokenStringLiteral, Value: `"old"`}},
+					}},
+					Name: ident("pair"),
+					Type: &TupleType{Elems: []TypeExpr{
+						&NamedType{Name: ident("T")},
+						&NamedType{Name: ident("T")},
+					}},
+				},
+			},
+		}},
+	}
+	f.Decls = []Decl{f.Concepts[0]}
+	return f
+}
+
+type countingVisitor struct {
+	nodes int
+	nils  int
+}
+
+func (v *countingVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		v.nils += 1
+	} else {
+		v.nodes += 1
+	}
+	return v
+}
+
+var _ = Describe("Walk and Inspect", func() {
+	It("Walk should visit every node and close every visit", func() {
+		v := &countingVisitor{}
+		Walk(v, testFile())
+
+		// 8 nodes for the declaration header, 7 for each field,
+		// 2 for the default value and 3 for the annotation
+		Expect(v.nodes).To(Equal(27))
+		Expect(v.nils).To(Equal(v.nodes))
+	})
+
+	It("Inspect should visit identifiers in source order", func() {
+		names := make([]string, 0)
+		Inspect(testFile(), func(n Node) bool {
+			if id, ok := n.(*Ident); ok {
+				names = append(names, id.Name)
+			}
+			return true
+		})
+
+		Expect(names).To(Equal([]string{
+			"TodoList", "T", "TypedList", "T", "items", "T", "deprecated", "pair", "T", "T",
+		}))
+	})
+
+	It("Walk should visit the declarations of a file in source order", func() {
+		//	enum Status { Open }
+		//	concept Issue {}
+		//	enum Priority { Low }
+		status := &EnumDecl{Name: ident("Status"), Members: []*EnumMember{{Name: ident("Open")}}}
+		issue := &ConceptDecl{Name: ident("Issue")}
+		priority := &EnumDecl{Name: ident("Priority"), Members: []*EnumMember{{Name: ident("Low")}}}
+		f := &File{
+			Decls:    []Decl{status, issue, priority},
+			Concepts: []*ConceptDecl{issue},
+			Enums:    []*EnumDecl{status, priority},
+		}
+
+		names := make([]string, 0)
+		Inspect(f, func(n Node) bool {
+			if id, ok := n.(*Ident); ok {
+				names = append(names, id.Name)
+			}
+			return true
+		})
+		Expect(names).To(Equal([]string{"Status", "Open", "Issue", "Priority", "Low"}))
+	})
+
+	It("Inspect should not descend when f returns false", func() {
+		fields := 0
+		idents := 0
+		Inspect(testFile(), func(n Node) bool {
+			switch n.(type) {
+			case *FieldDecl:
+				fields += 1
+				return false
+			case *Ident:
+				idents += 1
+			}
+			return true
+		})
+
+		Expect(fields).To(Equal(2))
+		Expect(idents).To(Equal(4))
+	})
+})
@@ -0,0 +1,11 @@
+// Package builtin holds the builtin library, which meme uses
+// when it cannot find the library on disk.
+package builtin
+
+import "embed"
+
+// FS holds the files of the library, e.g. typedlist.meme and
+// special/concept.meme.
+//
+//go:embed *.meme special
+var FS embed.FS
@@ -1,120 +1,193 @@
-// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at
-//
-//     http://www.apache.org/licenses/LICENSE-2.0
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
-
 package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
-	"strings"
+	"path/filepath"
 
-	"github.com/rhysd/abspath"
 	"github.com/spf13/cobra"
 
-	"github.com/riyanshkarani011235/meme/lexer"
+	"github.com/riyanshkarani011235/meme/concept"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/ir"
+	"github.com/riyanshkarani011235/meme/loader"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// the options of build
+var (
+	buildEmit   string
+	buildOutput string
 )
 
+// the set of the files loaded by the command
+var fset = token.NewFileSet()
+
 // buildCmd represents the build command
 var buildCmd = &cobra.Command{
-	Use:   "build",
+	Use:   "build [paths...]",
 	Short: "meme build builds the provided set of meme description files",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Long: `meme build loads and resolves the provided set of meme description files,
+along with the files they import. A path may be a file, a directory, whose
+whole tree is built, a glob pattern such as schema/*.meme, or ./... for the
+tree below the current directory, which is also what no path stands for.
+--include and --exclude select files by pattern within directories, e.g.
+--exclude=generated. The builtin library is loaded along with the files,
+from --builtin, $MEME_BUILTIN, or the first builtin/ directory found in the
+current directory, one of its parents, or next to the meme executable. If
+there is none, the copy of the library built into meme is used. Files
+import it with paths that start with builtin/, wherever they are:
+
+  import "builtin/time.meme"
+
+The manifest of the project, meme.yaml, is looked up in the current
+directory and its parents. Without paths, its sources and the ones of its
+dependencies are built. Its include and exclude patterns and its builtin
+directory apply to every build. Its generate targets are built along with
+the files; the ir target writes schema.ir.json in its output directory.
+
+--emit=ir writes the intermediate representation of the resolved schema, a
+versioned JSON document with every concept, its inherited fields, enum and
+relation, to the file given by -o, or to stdout. Tools load it back with
+the Go package github.com/riyanshkarani011235/meme/ir.`,
 	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		files := make([]string, len(args))
-		for i, arg := range args {
-			files[i] = getAbsoluteFilePath(arg)
+		if buildEmit != "" && buildEmit != "ir" {
+			fatalf("unknown output `%s`, expected `ir`", buildEmit)
 		}
-
-		build(files)
+		build(inputs(args))
 	},
 }
 
-func getAbsoluteFilePath(file string) string {
-	absolutePath, err := abspath.ExpandFrom(file)
-	if err != nil {
-		fmt.Println("Error: ", err)
-		os.Exit(1)
+func build(files []string) {
+	tree := resolve(files)
+
+	if buildEmit == "ir" {
+		writeIR(tree, buildOutput)
 	}
 
-	path := absolutePath.String()
-
-	// check if the file actually exists
-	if fi, err := os.Stat(path); err == nil {
-		// path exists, make sure that this is a file
-		// and not a directory
-		switch mode := fi.Mode(); {
-		case mode.IsRegular():
-			// do nothing
-		default:
-			fmt.Printf("Error: `%s` is not a file\n", path)
-			os.Exit(1)
+	if m := project(); m != nil {
+		for _, gen := range m.Generate {
+			if gen.Target == "ir" {
+				writeIR(tree, filepath.Join(m.Join(gen.Output), "schema.ir.json"))
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "warning: no generator for target `%s`, skipping it\n", gen.Target)
 		}
+	}
+}
 
-	} else if os.IsNotExist(err) {
-		// path does not exist
-		fmt.Printf("Error: file `%s` does not exits\n", path)
-		os.Exit(1)
-	} else {
-		// Schrodinger: file may or may not exist. See err for details.
-
-		// Therefore, do *NOT* use !os.IsNotExist(err) to test for file existence
-		fmt.Println("Error: ", err)
-		os.Exit(1)
+// writes the IR of the tree to the file at path, creating
+// its directory, or to stdout if path is "" or "-". Files are
+// named relative to the manifest of the project, if there is
+// one.
+func writeIR(tree *concept.ConceptTree, path string) {
+	var c ir.Config
+	if m := project(); m != nil {
+		c.Root = m.Dir()
+	}
+	schema := c.Build(tree, fset)
+	if path == "" || path == "-" {
+		if err := ir.Write(os.Stdout, schema); err != nil {
+			fatalf("%s", err)
+		}
+		return
 	}
 
-	if !strings.HasSuffix(path, ".meme") {
-		fmt.Printf("`%s` is not a meme file\n", path)
-		os.Exit(1)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fatalf("%s", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	if err := ir.Write(f, schema); err != nil {
+		f.Close()
+		fatalf("%s: %s", path, err)
 	}
+	if err := f.Close(); err != nil {
+		fatalf("%s: %s", path, err)
+	}
+}
 
-	return path
+// loads the given files along with the files they import,
+// then resolves them, printing every diagnostic to stderr
+// and exiting if one of them is an error
+func resolve(files []string) *concept.ConceptTree {
+	tree, diagnostics, sources := check(files)
+	if diagnostics.HasErrors() {
+		report(diagnostics, sources)
+	}
+	if len(diagnostics) > 0 {
+		diagnostics.Render(os.Stderr, sources)
+	}
+	return tree
 }
 
-func build(files []string) {
-	for _, file := range files {
-		data, err := ioutil.ReadFile(file)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		l := lexer.NewLexer(string(data))
+// loads the given files along with the files they import,
+// then resolves them. Returns the diagnostics found, with the
+// sources of the files read, and the tree if none of them is
+// an error. The files that could be loaded are resolved even
+// if others could not, so that their errors are reported
+// together.
+func check(files []string) (*concept.ConceptTree, diag.List, diag.Sources) {
+	builtin := relative(findBuiltin())
+	prog, err := loader.Config{Builtin: builtin}.Load(fset, files...)
+	diagnostics := diagnosticsOf(err)
+
+	tree := concept.NewConceptTree()
+	tree.SetBuiltin(builtin)
+	err = tree.Resolve(prog.Fset, prog.ASTs()...)
+	diagnostics = merge(diagnostics, diagnosticsOf(err))
+
+	diagnostics.Sort()
+	if diagnostics.HasErrors() {
+		return nil, diagnostics, prog.Sources
+	}
+	return tree, diagnostics, prog.Sources
+}
 
-		tokens := l.Tokenize()
+// returns the diagnostics of err, which the loader and the
+// resolver return as a diag.List. Other errors are wrapped in
+// a diagnostic without a location.
+func diagnosticsOf(err error) diag.List {
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case diag.List:
+		return err
+	default:
+		return diag.List{diag.Errorf(nil, diag.Span{}, "", "%s", err)}
+	}
+}
 
-		for _, token := range tokens {
-			fmt.Printf("%v\n", token)
+// appends to list the diagnostics of more that it does not
+// already report, e.g. an import both the loader and the
+// resolver found missing
+func merge(list, more diag.List) diag.List {
+	reported := make(map[diag.Code]map[diag.Span]bool)
+	for _, d := range list {
+		if reported[d.Code] == nil {
+			reported[d.Code] = make(map[diag.Span]bool)
+		}
+		reported[d.Code][d.Primary.Span] = true
+	}
+	for _, d := range more {
+		if !reported[d.Code][d.Primary.Span] {
+			list = append(list, d)
 		}
 	}
+	return list
+}
+
+// prints the diagnostics to stderr and exits
+func report(diagnostics diag.List, sources diag.Sources) {
+	diagnostics.Render(os.Stderr, sources)
+	os.Exit(1)
 }
 
 func init() {
 	rootCmd.AddCommand(buildCmd)
-
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// buildCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// buildCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	buildCmd.Flags().StringVar(&buildEmit, "emit", "", "the output to write: ir")
+	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "", "the file the output is written to, stdout if empty or -")
 }
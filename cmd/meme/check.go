@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+// the output format of check, "text" or "json"
+var checkFormat string
+
+var checkCmd = &cobra.Command{
+	Use:   "check [paths...]",
+	Short: "meme check type-checks a set of meme description files without generating output",
+	Long: `meme check lexes, parses and resolves the provided set of meme description
+files, along with the files they import, and reports every diagnostic. The
+paths are those of meme build: files, directories, glob patterns and ./...,
+which is also what no path stands for. The command exits with a non-zero
+status if there are errors, so that it can gate schema changes.
+
+Diagnostics are printed to stderr in the compiler style, or with
+--format=json to stdout as a JSON list of objects with their severity, code,
+message, and locations as lines and columns.`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if checkFormat != "text" && checkFormat != "json" {
+			fatalf("unknown format `%s`, expected `text` or `json`", checkFormat)
+		}
+
+		_, diagnostics, sources := check(inputs(args))
+		if checkFormat == "json" {
+			if diagnostics == nil {
+				diagnostics = diag.List{}
+			}
+			out, _ := json.MarshalIndent(diagnostics, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			diagnostics.Render(os.Stderr, sources)
+		}
+
+		if diagnostics.HasErrors() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().StringVar(&checkFormat, "format", "text", "the format of the diagnostics, text or json")
+}
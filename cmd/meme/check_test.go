@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+var _ = Describe("check", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "meme-check")
+		Expect(err).NotTo(HaveOccurred())
+		builtinDir = filepath.Join("..", "..", "builtin")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		builtinDir = ""
+	})
+
+	// writes the file at name in the temporary directory
+	write := func(name, src string) {
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644)).To(Succeed())
+	}
+
+	// returns the codes of the diagnostics
+	codes := func(diagnostics diag.List) []diag.Code {
+		list := make([]diag.Code, len(diagnostics))
+		for i, d := range diagnostics {
+			list[i] = d.Code
+		}
+		return list
+	}
+
+	It("Should resolve the files that loaded along with the errors of the loader", func() {
+		write("a.meme", `concept A { required b B }`)
+		write("b.meme", `import "missing.meme"
+concept B { required c Missing }`)
+
+		tree, diagnostics, sources := check(inputs([]string{dir}))
+		Expect(tree).To(BeNil())
+		Expect(codes(diagnostics)).To(Equal([]diag.Code{
+			diag.CodeNotImported,
+			diag.CodeImportNotFound,
+			diag.CodeUndefinedName,
+		}))
+		Expect(sources).To(ContainElement(`concept A { required b B }`))
+	})
+	It("Should wrap errors that are not diagnostics", func() {
+		Expect(diagnosticsOf(nil)).To(BeNil())
+
+		list := diag.List{diag.Errorf(nil, diag.Span{}, diag.CodeImportCycle, "cycle")}
+		Expect(diagnosticsOf(list)).To(Equal(list))
+
+		wrapped := diagnosticsOf(errors.New("permission denied"))
+		Expect(wrapped).To(HaveLen(1))
+		Expect(wrapped.HasErrors()).To(BeTrue())
+		Expect(wrapped.Error()).To(Equal("permission denied"))
+	})
+})
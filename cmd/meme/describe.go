@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// describeCmd represents the describe command
+var describeCmd = &cobra.Command{
+	Use:   "describe <concept|enum|relation> [paths...]",
+	Short: "meme describe prints every field of a concept, inherited ones included",
+	Long: `meme describe resolves the provided set of meme description files and
+prints the given concept with all of its fields, including the ones it
+inherits, with the type arguments of generic ancestors substituted. Enums
+are printed with the value of every member, and relations with their ends
+and properties. Doc comments are printed above what they document.
+Concepts and enums declared in a package are named with their package, as
+in scrum.Issue.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tree := resolve(inputs(args[1:]))
+		if e := tree.LookupEnum(args[0]); e != nil {
+			fmt.Println(e.Describe())
+			return
+		}
+
+		c := tree.Lookup(args[0])
+		if c == nil {
+			fatalf("concept, enum or relation `%s` is not declared", args[0])
+		}
+
+		if rel := c.Relation(); rel != nil {
+			fmt.Println(rel.Describe())
+			return
+		}
+		fmt.Println(c.Describe())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+}
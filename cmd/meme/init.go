@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riyanshkarani011235/meme/manifest"
+)
+
+// the options of init
+var (
+	initTemplate  string
+	initGenerator string
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [dir]",
+	Short: "meme init creates a new meme project",
+	Long: `meme init creates a meme project in the given directory, or in the current
+one: a meme.yaml manifest, a schema/ directory with the concepts of an
+example, a data/ directory with its instances if it has some, and a
+.gitignore for the generated code. The schema imports the builtin library
+with builtin/ paths, and meme carries a copy of the library, so the project
+builds wherever it is moved. --generate adds a code generation target to
+the manifest, writing to gen/<target>. Existing files are never
+overwritten. --template chooses the example the project starts from:
+
+` + templateHelp(),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		created, err := initProject(dir, initTemplate, initGenerator)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		for _, path := range created {
+			fmt.Printf("created %s\n", path)
+		}
+	},
+}
+
+// writes the files of a new project in dir, from the given
+// template, with a generator for target if it is not empty.
+// Returns the paths of the files written. Nothing is written
+// if one of the files exists.
+func initProject(dir string, template string, target string) ([]string, error) {
+	t, ok := templates[template]
+	if !ok {
+		return nil, fmt.Errorf("unknown template `%s`, expected one of %s", template, templateNames())
+	}
+
+	schema, err := t.files()
+	if err != nil {
+		return nil, err
+	}
+	files := append([]templateFile{
+		{manifest.FileName, initManifest(target)},
+		{".gitignore", "/gen/\n"},
+	}, schema...)
+
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dir, f.path)); err == nil {
+			return nil, fmt.Errorf("`%s` already exists", filepath.Join(dir, f.path))
+		}
+	}
+
+	created := make([]string, 0, len(files))
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return created, err
+		}
+		if err := ioutil.WriteFile(path, []byte(f.content), 0644); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+// returns the manifest of a new project, with a generator for
+// target if it is not empty
+func initManifest(target string) string {
+	generate := `# code generation targets, e.g.
+# generate:
+#   - target: ir
+#     output: gen/ir
+`
+	if target != "" {
+		generate = fmt.Sprintf("generate:\n  - target: %s\n    output: gen/%s\n", target, target)
+	}
+
+	return fmt.Sprintf(`# the manifest of the project, see meme build --help
+language: "%s"
+sources:
+  - schema/...
+%s
+# the levels of lint rules: error, warning or off
+lint: {}
+`, manifest.LanguageVersion, generate)
+}
+
+// returns the names of the templates, sorted
+func templateNames() string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// returns a line for every template, with its description
+func templateHelp() string {
+	lines := make([]string, 0, len(templates))
+	for _, name := range strings.Split(templateNames(), ", ") {
+		lines = append(lines, fmt.Sprintf("  %-10s %s", name, templates[name].description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initTemplate, "template", "todolist", "the example the project starts from: "+templateNames())
+	initCmd.Flags().StringVar(&initGenerator, "generate", "", "a code generation target to add to the manifest")
+}
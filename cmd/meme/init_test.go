@@ -0,0 +1,147 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/manifest"
+)
+
+var _ = Describe("init", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "meme-init")
+		Expect(err).NotTo(HaveOccurred())
+		builtinDir = filepath.Join("..", "..", "builtin")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		builtinDir = ""
+	})
+
+	// returns the paths of the files of the project, relative
+	// to it
+	relativePaths := func(root string, paths []string) []string {
+		list := make([]string, len(paths))
+		for i, path := range paths {
+			rel, err := filepath.Rel(root, path)
+			Expect(err).NotTo(HaveOccurred())
+			list[i] = filepath.ToSlash(rel)
+		}
+		return list
+	}
+
+	It("Should create a project from an example that builds wherever it is moved", func() {
+		project := filepath.Join(dir, "board")
+		created, err := initProject(project, "scrum", "ir")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(relativePaths(project, created)).To(Equal([]string{
+			"meme.yaml",
+			".gitignore",
+			"schema/board.meme",
+			"schema/category.meme",
+			"schema/deadline.meme",
+			"schema/epic.meme",
+			"schema/issue.meme",
+		}))
+
+		m, err := manifest.Read(filepath.Join(project, manifest.FileName))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Sources).To(Equal([]string{"schema/..."}))
+		Expect(m.Builtin).To(BeEmpty())
+		Expect(m.Generate).To(Equal([]*manifest.Generator{{Target: "ir", Output: "gen/ir"}}))
+
+		// the files are those of the example, which import the
+		// builtin library without a path to it
+		example, err := ioutil.ReadFile("../../examples/scrum_board/deadline.meme")
+		Expect(err).NotTo(HaveOccurred())
+		deadline, err := ioutil.ReadFile(filepath.Join(project, "schema", "deadline.meme"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(deadline)).To(Equal(string(example)))
+		Expect(string(deadline)).To(ContainSubstring(`import "builtin/time.meme"`))
+
+		moved := filepath.Join(dir, "elsewhere", "board")
+		Expect(os.MkdirAll(filepath.Dir(moved), 0755)).To(Succeed())
+		Expect(os.Rename(project, moved)).To(Succeed())
+
+		tree, diagnostics, _ := check(inputs([]string{moved}))
+		Expect(diagnostics).To(BeEmpty())
+		Expect(tree.Lookup("scrum.Deadline").Parent().Name()).To(Equal("Time"))
+	})
+
+	It("Should create a project that builds outside the repository with the builtin library of meme", func() {
+		project := filepath.Join(dir, "todo")
+		_, err := initProject(project, "todolist", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		// nothing points to the builtin/ directory of the
+		// repository, and it is not above the project
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(project)).To(Succeed())
+		defer os.Chdir(wd)
+		defer os.Setenv("MEME_BUILTIN", os.Getenv("MEME_BUILTIN"))
+		defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+		Expect(os.Unsetenv("MEME_BUILTIN")).To(Succeed())
+		Expect(os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))).To(Succeed())
+		builtinDir, projectFound = "", false
+		defer func() { projectFound = false }()
+
+		library := findBuiltin()
+		Expect(library).To(HavePrefix(filepath.Join(dir, "cache", "meme", "builtin-")))
+		Expect(filepath.Join(library, "special", "concept.meme")).To(BeARegularFile())
+		Expect(findBuiltin()).To(Equal(library))
+
+		tree, diagnostics, _ := check(inputs(nil))
+		Expect(diagnostics).To(BeEmpty())
+		Expect(tree.Lookup("TodoList").Parent().Name()).To(Equal("TypedList"))
+	})
+
+	It("Should copy the instances of the example to data/", func() {
+		created, err := initProject(dir, "todolist", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(relativePaths(dir, created)).To(ContainElement("data/todolist.json"))
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, manifest.FileName))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring("builtin"))
+		Expect(string(data)).To(ContainSubstring("# generate:"))
+
+		_, diagnostics, _ := check(inputs([]string{dir}))
+		Expect(diagnostics).To(BeEmpty())
+	})
+
+	It("Should not overwrite existing files", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.o\n"), 0644)).To(Succeed())
+
+		created, err := initProject(dir, "todolist", "")
+		Expect(err).To(MatchError("`" + filepath.Join(dir, ".gitignore") + "` already exists"))
+		Expect(created).To(BeEmpty())
+		_, err = os.Stat(filepath.Join(dir, manifest.FileName))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("Should reject unknown templates", func() {
+		_, err := initProject(dir, "kanban", "")
+		Expect(err).To(MatchError("unknown template `kanban`, expected one of scrum, todolist"))
+	})
+
+	It("Should have an example for every template", func() {
+		for name, t := range templates {
+			files, err := t.files()
+			Expect(err).NotTo(HaveOccurred(), name)
+			Expect(files).NotTo(BeEmpty(), name)
+			for _, f := range files {
+				Expect(strings.HasPrefix(f.path, "schema/") || strings.HasPrefix(f.path, "data/")).To(BeTrue(), f.path)
+			}
+		}
+	})
+})
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/riyanshkarani011235/meme/builtin"
+	"github.com/riyanshkarani011235/meme/loader"
+	"github.com/riyanshkarani011235/meme/manifest"
+)
+
+// the include and exclude patterns and the options of the
+// builtin library given on the command line
+var (
+	includePatterns []string
+	excludePatterns []string
+	builtinDir      string
+	noBuiltin       bool
+)
+
+// the manifest of the project, once it is looked up
+var (
+	projectManifest *manifest.Manifest
+	projectFound    bool
+)
+
+// returns the manifest of the project the current directory
+// is in, or nil if there is none. Exits if it is invalid.
+func project() *manifest.Manifest {
+	if !projectFound {
+		m, err := manifest.Load(".")
+		if err != nil {
+			fatalf("%s", err)
+		}
+		projectManifest, projectFound = m, true
+	}
+	return projectManifest
+}
+
+// returns the files named by the patterns given on the
+// command line, followed by the files of the builtin
+// library. Without patterns, the sources of the project and
+// of its dependencies are used if there is a manifest, ./...
+// otherwise. The include and exclude patterns of the
+// manifest add to the ones given on the command line. Exits
+// if a pattern names no file. The paths are relative to the
+// current directory, so that a file imported by another one
+// is named the same way as when it is given directly.
+func inputs(patterns []string) []string {
+	filter := loader.Filter{Include: includePatterns, Exclude: excludePatterns}
+	if m := project(); m != nil {
+		filter.Include = append(filter.Include, m.Include...)
+		filter.Exclude = append(filter.Exclude, m.Exclude...)
+	}
+
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+		if m := project(); m != nil {
+			var err error
+			if patterns, err = m.Inputs(); err != nil {
+				fatalf("%s", err)
+			}
+		}
+	}
+
+	files, err := loader.Inputs(patterns, filter)
+	if err != nil {
+		fatalf("%s", err)
+	}
+
+	if dir := findBuiltin(); dir != "" {
+		library, err := loader.Inputs([]string{dir}, loader.Filter{})
+		if err != nil {
+			fatalf("cannot load the builtin library: %s", err)
+		}
+		files = append(files, library...)
+	}
+
+	for i, name := range files {
+		files[i] = relative(name)
+	}
+	return files
+}
+
+// returns the directory of the builtin library, or "" if it
+// is disabled. It is the directory given by --builtin, the
+// manifest or $MEME_BUILTIN, or else the first builtin/
+// directory holding special/concept.meme in the current
+// directory or one of its parents, or next to the meme
+// executable. Without one, the library embedded in meme is
+// written to the cache directory and used.
+func findBuiltin() string {
+	if noBuiltin {
+		return ""
+	}
+	if builtinDir != "" {
+		return builtinDir
+	}
+	if m := project(); m != nil && m.Builtin != "" {
+		return m.Join(m.Builtin)
+	}
+	if dir := os.Getenv("MEME_BUILTIN"); dir != "" {
+		return dir
+	}
+
+	candidates := make([]string, 0)
+	if wd, err := os.Getwd(); err == nil {
+		for dir := wd; ; dir = filepath.Dir(dir) {
+			candidates = append(candidates, filepath.Join(dir, "builtin"))
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), "builtin"))
+	}
+
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, "special", "concept.meme")); err == nil {
+			return dir
+		}
+	}
+	return embeddedBuiltin()
+}
+
+// writes the builtin library embedded in meme to the cache
+// directory, once for every version of the library, and
+// returns the directory it is in. Returns "" if it cannot be
+// written.
+func embeddedBuiltin() string {
+	files := make(map[string][]byte)
+	hash := sha256.New()
+	err := fs.WalkDir(builtin.FS, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(builtin.FS, name)
+		if err != nil {
+			return err
+		}
+		files[name] = data
+		fmt.Fprintf(hash, "%s %d\n", name, len(data))
+		hash.Write(data)
+		return nil
+	})
+	if err != nil {
+		return ""
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	dir := filepath.Join(cache, "meme", fmt.Sprintf("builtin-%x", hash.Sum(nil)[:8]))
+	if _, err := os.Stat(dir); err == nil {
+		return dir
+	}
+
+	// the library is written next to its directory, then
+	// renamed, so that it is never seen half written
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return ""
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "builtin-")
+	if err != nil {
+		return ""
+	}
+	defer os.RemoveAll(tmp)
+
+	for name, data := range files {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return ""
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return ""
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return ""
+		}
+		// written by another meme in the meantime
+	}
+	return dir
+}
+
+// returns path relative to the current directory, or path
+// if it has no such form
+func relative(path string) string {
+	wd, err := os.Getwd()
+	if err != nil || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringSliceVar(&includePatterns, "include", nil, "only build the files matching one of these patterns")
+	flags.StringSliceVar(&excludePatterns, "exclude", nil, "do not build the files matching one of these patterns")
+	flags.StringVar(&builtinDir, "builtin", "", "the directory of the builtin library, instead of looking it up")
+	flags.BoolVar(&noBuiltin, "no-builtin", false, "do not load the builtin library")
+}
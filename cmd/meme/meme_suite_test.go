@@ -0,0 +1,13 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestMeme(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Meme Suite")
+}
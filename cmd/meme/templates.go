@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/examples"
+)
+
+// a project created by meme init from one of the examples.
+// Its .meme files go to schema/ and its .json files to
+// data/; the manifest is written by meme init.
+type projectTemplate struct {
+	example     string // the directory of the example
+	description string
+}
+
+type templateFile struct {
+	path    string // relative to the project
+	content string
+}
+
+// the templates of meme init
+var templates = map[string]projectTemplate{
+	"todolist": {"todolist", "a list of things to do, as in examples/todolist"},
+	"scrum":    {"scrum_board", "a scrum board with issues, epics and deadlines, as in examples/scrum_board"},
+}
+
+// returns the files of the example, sorted by path
+func (t projectTemplate) files() ([]templateFile, error) {
+	files := make([]templateFile, 0)
+	err := fs.WalkDir(examples.FS, t.example, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		var dir string
+		switch path.Ext(name) {
+		case ".meme":
+			dir = "schema"
+		case ".json":
+			dir = "data"
+		default:
+			return nil
+		}
+
+		data, err := fs.ReadFile(examples.FS, name)
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(name, t.example+"/")
+		files = append(files, templateFile{path.Join(dir, rel), string(data)})
+		return nil
+	})
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, err
+}
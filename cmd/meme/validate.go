@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riyanshkarani011235/meme/validate"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <concept> <data.json> [paths...]",
+	Short: "meme validate checks that a JSON document is an instance of a concept",
+	Long: `meme validate resolves the provided set of meme description files and
+checks that the JSON document is an instance of the given concept. Every
+mismatch is reported with its path in the document. If the document is valid,
+it is printed with the default value of every missing optional field filled in.
+A concept declared in a package is named with its package, as in scrum.Issue.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		tree := resolve(inputs(args[2:]))
+		c := tree.Lookup(args[0])
+		if c == nil {
+			fatalf("concept `%s` is not declared", args[0])
+		}
+
+		raw, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			fatalf("%s", err)
+		}
+
+		var data interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			fatalf("%s is not valid JSON: %s", args[1], err)
+		}
+
+		result, err := validate.Instance(c, data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,320 @@
+package concept
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+// Annotation is an annotation of a concept or a field, such
+// as @min(0), with its arguments resolved to values.
+// Annotations that are not built in are kept as they are
+// written, for code generators and plugins to read.
+type Annotation struct {
+	Name string
+	Args []Value
+	decl *ast.Annotation
+}
+
+func (a *Annotation) Decl() *ast.Annotation { return a.decl }
+
+// IsBuiltin reports whether the annotation is one of the
+// annotations checked by the resolver.
+func (a *Annotation) IsBuiltin() bool {
+	_, ok := builtinAnnotations[a.Name]
+	return ok
+}
+
+func (a *Annotation) String() string {
+	if a.decl != nil && !a.decl.Rparen.IsValid() {
+		return "@" + a.Name
+	}
+	return fmt.Sprintf("@%s(%s)", a.Name, formatValueList(a.Args))
+}
+
+// Annotations returns the annotations of the concept in the
+// order they are written.
+func (c *Concept) Annotations() []*Annotation { return c.annotations }
+
+// Annotation returns the first annotation of the concept
+// with the given name, or nil if there is none.
+func (c *Concept) Annotation(name string) *Annotation {
+	return findAnnotation(c.annotations, name)
+}
+
+// Annotations returns the annotations of the field in the
+// order they are written.
+func (f *Field) Annotations() []*Annotation { return f.annotations }
+
+// Annotation returns the first annotation of the field with
+// the given name, or nil if there is none.
+func (f *Field) Annotation(name string) *Annotation {
+	return findAnnotation(f.annotations, name)
+}
+
+// returns doc, the text of a doc comment, or the argument of
+// the @doc annotation in list if doc is empty
+func docText(doc string, list []*Annotation) string {
+	if doc != "" {
+		return doc
+	}
+	if a := findAnnotation(list, "doc"); a != nil && len(a.Args) > 0 {
+		if s, ok := a.Args[0].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func findAnnotation(list []*Annotation, name string) *Annotation {
+	for _, a := range list {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// a built-in annotation, the kinds of its arguments and what
+// it can be used on
+type builtin struct {
+	params    []PrimitiveKind
+	required  int             // the number of params that must be given
+	onConcept bool            // if it can annotate a concept
+	applies   func(Type) bool // the field types it can annotate, nil for any
+	target    string          // describes what it can annotate
+}
+
+var builtinAnnotations = map[string]builtin{
+	// bounds of an integer, inclusive
+	"min": {params: []PrimitiveKind{Integer}, required: 1, applies: isKind(Integer), target: "integer fields"},
+	"max": {params: []PrimitiveKind{Integer}, required: 1, applies: isKind(Integer), target: "integer fields"},
+
+	// @length(n) is an exact length, @length(min, max) an
+	// inclusive range of lengths, of a string or a list
+	"length": {params: []PrimitiveKind{Integer, Integer}, required: 1, applies: hasLength, target: "string and list fields"},
+
+	// a regular expression a string must match
+	"pattern": {params: []PrimitiveKind{String}, required: 1, applies: isKind(String), target: "string fields"},
+
+	// documentation, with an optional reason for deprecation
+	"deprecated": {params: []PrimitiveKind{String}, required: 0, onConcept: true, target: "concepts and fields"},
+	"since":      {params: []PrimitiveKind{String}, required: 1, onConcept: true, target: "concepts and fields"},
+	"doc":        {params: []PrimitiveKind{String}, required: 1, onConcept: true, target: "concepts and fields"},
+}
+
+func isKind(kind PrimitiveKind) func(Type) bool {
+	return func(t Type) bool {
+		p, ok := t.(*PrimitiveType)
+		return ok && p.Kind == kind
+	}
+}
+
+func hasLength(t Type) bool {
+	if _, ok := t.(*ListType); ok {
+		return true
+	}
+	return isKind(String)(t)
+}
+
+// resolves the annotations of concept c
+func (r *resolver) resolveConceptAnnotations(c *Concept) {
+	c.annotations = r.resolveAnnotations(c.decl.Annotations, func(a *Annotation, b builtin) bool {
+		if !b.onConcept {
+			kind := "concept"
+			if c.relation != nil {
+				kind = "relation"
+			}
+			r.errorf(a.decl, diag.CodeMisplacedAnnotation, "@%s cannot be used on %s `%s`", a.Name, kind, c).
+				WithNote("@%s applies to %s", a.Name, b.target)
+			return false
+		}
+		return true
+	})
+}
+
+// resolves the annotations of field f, after its type
+func (r *resolver) resolveFieldAnnotations(f *Field) {
+	f.annotations = r.resolveAnnotations(f.decl.Annotations, func(a *Annotation, b builtin) bool {
+		if b.applies != nil && f.typ != nil && !b.applies(f.typ) {
+			r.errorf(a.decl, diag.CodeMisplacedAnnotation, "@%s cannot be used on field `%s` of type `%s`", a.Name, f.name, f.typ).
+				WithNote("@%s applies to %s", a.Name, b.target)
+			return false
+		}
+		return true
+	})
+
+	r.checkBounds(f.annotations)
+}
+
+// resolves the arguments of annotations and checks the
+// built-in ones with target, which reports whether they are
+// used on the right kind of declaration. Built-in
+// annotations that are invalid are left out of the result.
+func (r *resolver) resolveAnnotations(decls []*ast.Annotation, target func(*Annotation, builtin) bool) []*Annotation {
+	list := make([]*Annotation, 0, len(decls))
+
+	for _, d := range decls {
+		a := &Annotation{Name: d.Name.Name, decl: d}
+		b, isBuiltin := builtinAnnotations[a.Name]
+
+		if !isBuiltin {
+			// arguments of other annotations are untyped, and
+			// identifiers are kept as names
+			ok := true
+			for _, arg := range d.Args {
+				v, m := untypedValue(arg, true)
+				if m != nil {
+					r.errorf(m.expr, diag.CodeInvalidAnnotation, "invalid argument to @%s: %s", a.Name, m.message)
+					ok = false
+				}
+				a.Args = append(a.Args, v)
+			}
+			if ok {
+				list = append(list, a)
+			}
+			continue
+		}
+
+		if previous := findAnnotation(list, a.Name); previous != nil {
+			r.errorf(d, diag.CodeDuplicateAnnotation, "duplicate annotation @%s", a.Name).
+				WithSecondary(diag.SpanOf(previous.decl), "previous @%s", a.Name)
+			continue
+		}
+
+		if r.resolveBuiltinArgs(a, b) && target(a, b) {
+			list = append(list, a)
+		}
+	}
+
+	return list
+}
+
+// resolves the arguments of a built-in annotation and checks
+// their number, their kinds and their values
+func (r *resolver) resolveBuiltinArgs(a *Annotation, b builtin) bool {
+	d := a.decl
+	if n := len(d.Args); n < b.required || n > len(b.params) {
+		r.errorf(d, diag.CodeInvalidAnnotation, "@%s takes %s, found %d", a.Name, argumentCount(b), n)
+		return false
+	}
+
+	for i, arg := range d.Args {
+		v, m := valueOf(arg, &PrimitiveType{b.params[i]})
+		if m != nil {
+			r.errorf(m.expr, diag.CodeInvalidAnnotation, "invalid argument to @%s: %s", a.Name, m.message)
+			return false
+		}
+		a.Args = append(a.Args, v)
+	}
+
+	switch a.Name {
+	case "length":
+		for i, v := range a.Args {
+			if v.(int64) < 0 {
+				r.errorf(d.Args[i], diag.CodeInvalidAnnotation, "invalid argument to @length: a length cannot be negative")
+				return false
+			}
+		}
+		if len(a.Args) == 2 && a.Args[0].(int64) > a.Args[1].(int64) {
+			r.errorf(d, diag.CodeInvalidAnnotation, "invalid arguments to @length: the minimum %d is greater than the maximum %d",
+				a.Args[0], a.Args[1])
+			return false
+		}
+
+	case "pattern":
+		if _, err := regexp.Compile(a.Args[0].(string)); err != nil {
+			r.errorf(d.Args[0], diag.CodeInvalidAnnotation, "invalid argument to @pattern: %s", err)
+			return false
+		}
+	}
+
+	return true
+}
+
+// checks that the bound of @min is not greater than the
+// bound of @max
+func (r *resolver) checkBounds(list []*Annotation) {
+	lower, upper := findAnnotation(list, "min"), findAnnotation(list, "max")
+	if lower == nil || upper == nil {
+		return
+	}
+
+	if lower.Args[0].(int64) > upper.Args[0].(int64) {
+		r.errorf(upper.decl, diag.CodeInvalidAnnotation, "%s is less than %s", upper, lower).
+			WithSecondary(diag.SpanOf(lower.decl), "lower bound set here")
+	}
+}
+
+// checks v, the default value of field f, against the
+// constraints set by the annotations of f. Returns false if
+// it breaks one of them.
+func (r *resolver) checkDefault(f *Field, v Value) bool {
+	broken := func(a *Annotation, format string, args ...interface{}) bool {
+		r.errorf(f.decl.Default, diag.CodeInvalidDefault, "invalid default value for field `%s`: %s", f.name, fmt.Sprintf(format, args...)).
+			WithSecondary(diag.SpanOf(a.decl), "constraint set here")
+		return false
+	}
+
+	if a := f.Annotation("min"); a != nil {
+		if n, ok := v.(int64); ok && n < a.Args[0].(int64) {
+			return broken(a, "%d is less than the minimum %d", n, a.Args[0])
+		}
+	}
+
+	if a := f.Annotation("max"); a != nil {
+		if n, ok := v.(int64); ok && n > a.Args[0].(int64) {
+			return broken(a, "%d is greater than the maximum %d", n, a.Args[0])
+		}
+	}
+
+	if a := f.Annotation("length"); a != nil {
+		n := -1
+		switch v := v.(type) {
+		case string:
+			n = utf8.RuneCountInString(v)
+		case ListValue:
+			n = len(v)
+		}
+
+		min, max := a.Args[0].(int64), a.Args[len(a.Args)-1].(int64)
+		switch {
+		case n < 0:
+			// not a string or a list
+		case len(a.Args) == 1 && int64(n) != min:
+			return broken(a, "expected a length of %d, found %d", min, n)
+		case int64(n) < min || int64(n) > max:
+			return broken(a, "expected a length between %d and %d, found %d", min, max, n)
+		}
+	}
+
+	if a := f.Annotation("pattern"); a != nil {
+		if s, ok := v.(string); ok && !regexp.MustCompile(a.Args[0].(string)).MatchString(s) {
+			return broken(a, "string %q does not match the pattern %q", s, a.Args[0])
+		}
+	}
+
+	return true
+}
+
+// describes the number of arguments of a built-in annotation
+func argumentCount(b builtin) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return fmt.Sprintf("%d arguments", n)
+	}
+
+	switch {
+	case b.required == len(b.params):
+		return plural(b.required)
+	case b.required == 0 && len(b.params) == 1:
+		return "at most 1 argument"
+	default:
+		return fmt.Sprintf("%d or %s", b.required, plural(len(b.params)))
+	}
+}
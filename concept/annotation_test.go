@@ -0,0 +1,129 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+var _ = Describe("Annotations", func() {
+	Context("Resolving valid annotations", func() {
+		It("Should resolve built-in and custom annotations", func() {
+			tree, err := resolve(parseSources(
+				`@doc("a unit of work") @since("1.2")
+				concept Ticket {
+					@pattern("^[A-Z]+-[0-9]+$") required key string
+					@min(0) @max(13) optional points integer
+					@length(1, 5) @deprecated optional tags [string]
+					@ui_widget("slider", [1, 2], vertical) @ui_hidden optional rank integer
+				}
+				concept Bug extends Ticket {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			ticket := tree.Lookup("Ticket")
+			Expect(ticket.Annotation("doc").Args).To(Equal([]Value{"a unit of work"}))
+			Expect(ticket.Annotations()).To(HaveLen(2))
+
+			points := ticket.LookupField("points")
+			Expect(points.Annotation("min").Args).To(Equal([]Value{int64(0)}))
+			Expect(points.Annotation("max").Args).To(Equal([]Value{int64(13)}))
+			Expect(points.Annotation("length")).To(BeNil())
+
+			rank := ticket.LookupField("rank")
+			Expect(rank.Annotations()).To(HaveLen(2))
+			Expect(rank.Annotation("ui_widget").Args).To(Equal([]Value{"slider", ListValue{int64(1), int64(2)}, Name("vertical")}))
+			Expect(rank.Annotation("ui_widget").IsBuiltin()).To(BeFalse())
+			Expect(rank.Annotation("ui_hidden").Args).To(BeNil())
+
+			// annotations are inherited with their fields
+			Expect(tree.Lookup("Bug").Describe()).To(Equal(
+				"concept Bug extends Ticket {\n" +
+					"\t@pattern(\"^[A-Z]+-[0-9]+$\") required key string // from Ticket\n" +
+					"\t@min(0) @max(13) optional points integer // from Ticket\n" +
+					"\t@length(1, 5) @deprecated optional tags [string] // from Ticket\n" +
+					"\t@ui_widget(\"slider\", [1, 2], vertical) @ui_hidden optional rank integer // from Ticket\n" +
+					"}"))
+			Expect(ticket.Describe()).To(HavePrefix(`@doc("a unit of work") @since("1.2") concept Ticket {`))
+		})
+	})
+
+	Context("Checking built-in annotations", func() {
+		It("Should report annotations on the wrong field types", func() {
+			_, err := resolve(parseSources(
+				`@min(1) concept Task {
+					@min(0) required title string
+					@pattern("a") required count integer
+					@length(2) required done boolean
+					@max(3) required item oneof(integer, string)
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:1: @min cannot be used on concept `Task`",
+				"a.meme:2:6: @min cannot be used on field `title` of type `string`",
+				"a.meme:3:6: @pattern cannot be used on field `count` of type `integer`",
+				"a.meme:4:6: @length cannot be used on field `done` of type `boolean`",
+				"a.meme:5:6: @max cannot be used on field `item` of type `oneof(integer, string)`",
+			}, "\n")))
+		})
+
+		It("Should check default values against the annotations of their field", func() {
+			_, err := resolve(parseSources(
+				`concept Task {
+					@max(5) optional p integer = 30
+					@min(1) optional q integer = 0
+					@length(2) optional r string = "abc"
+					@length(1, 2) optional s [integer] = []
+					@pattern("^a") optional t string = "b"
+					@min(1) @max(5) @length(1) @pattern("^a") optional u integer = 3
+					@length(1, 3) @pattern("^a") optional v string = "ab"
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:2:35: invalid default value for field `p`: 30 is greater than the maximum 5",
+				"a.meme:3:35: invalid default value for field `q`: 0 is less than the minimum 1",
+				"a.meme:4:37: invalid default value for field `r`: expected a length of 2, found 3",
+				"a.meme:5:43: invalid default value for field `s`: expected a length between 1 and 2, found 0",
+				"a.meme:6:41: invalid default value for field `t`: string \"b\" does not match the pattern \"^a\"",
+				"a.meme:7:22: @length cannot be used on field `u` of type `integer`",
+				"a.meme:7:33: @pattern cannot be used on field `u` of type `integer`",
+			}, "\n")))
+
+			list := err.(diag.List)
+			Expect(list[0].Code).To(Equal(diag.CodeInvalidDefault))
+			Expect(list[0].Secondary[0].Message).To(Equal("constraint set here"))
+		})
+
+		It("Should report invalid arguments", func() {
+			_, err := resolve(parseSources(
+				`concept Task {
+					@min required a integer
+					@max("3") required b integer
+					@length(1, 2, 3) required c string
+					@length(-1) required d string
+					@length(5, 2) required e string
+					@pattern("(") required f string
+					@doc required g string
+					@deprecated(1) required h string
+					@min(5) @max(2) required i integer
+					@doc("one") @doc("two") required j string
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:2:6: @min takes 1 argument, found 0",
+				"a.meme:3:11: invalid argument to @max: cannot use \"3\" as a value of type `integer`",
+				"a.meme:4:6: @length takes 1 or 2 arguments, found 3",
+				"a.meme:5:14: invalid argument to @length: a length cannot be negative",
+				"a.meme:6:6: invalid arguments to @length: the minimum 5 is greater than the maximum 2",
+				"a.meme:7:15: invalid argument to @pattern: error parsing regexp: missing closing ): `(`",
+				"a.meme:8:6: @doc takes 1 argument, found 0",
+				"a.meme:9:18: invalid argument to @deprecated: cannot use 1 as a value of type `string`",
+				"a.meme:10:14: @max(2) is less than @min(5)",
+				"a.meme:11:18: duplicate annotation @doc",
+			}, "\n")))
+		})
+	})
+})
@@ -1,16 +1,134 @@
 package concept
 
+import (
+	"sort"
+
+	"github.com/riyanshkarani011235/meme/ast"
+)
+
+// the name of the root of every concept tree
+const rootConceptName = "Concept"
+
 type Concept struct {
 	parent   *Concept
 	children []*Concept
+
+	name        string
+	pkg         string // the package of the concept, "" if it has none
+	doc         string // the text of the doc comment
+	annotations []*Annotation
+	typeParams  []*TypeParam
+	extends     *ConceptType // the instantiated parent, nil for the root
+	fields      []*Field
+	allFields   []*Field         // fields including inherited ones
+	decl        *ast.ConceptDecl // nil if never declared in a file
+	file        string           // the file decl was read from
+	relation    *Relation        // nil if the concept is not declared as a relation
+}
+
+func (c *Concept) Name() string             { return c.name }
+func (c *Concept) Package() string          { return c.pkg }
+func (c *Concept) Parent() *Concept         { return c.parent }
+func (c *Concept) Children() []*Concept     { return c.children }
+func (c *Concept) TypeParams() []*TypeParam { return c.typeParams }
+func (c *Concept) Extends() *ConceptType    { return c.extends }
+func (c *Concept) Fields() []*Field         { return c.fields }
+func (c *Concept) Decl() *ast.ConceptDecl   { return c.decl }
+func (c *Concept) File() string             { return c.file }
+func (c *Concept) IsGeneric() bool          { return len(c.typeParams) > 0 }
+func (c *Concept) String() string           { return c.QualifiedName() }
+
+// QualifiedName returns the name of the concept prefixed
+// with its package, as in scrum.Issue.
+func (c *Concept) QualifiedName() string { return qualify(c.pkg, c.name) }
+
+// Doc returns the text of the doc comment of the concept, or
+// the argument of its @doc annotation if it has no doc
+// comment.
+func (c *Concept) Doc() string { return docText(c.doc, c.annotations) }
+
+// Field is a field declared directly on a concept.
+type Field struct {
+	owner        *Concept
+	name         string
+	required     bool
+	typ          Type
+	defaultValue Value  // nil if the field has no default value
+	doc          string // the text of the doc comment
+	annotations  []*Annotation
+	decl         *ast.FieldDecl
 }
 
+func (f *Field) Owner() *Concept      { return f.owner }
+func (f *Field) Name() string         { return f.name }
+func (f *Field) Required() bool       { return f.required }
+func (f *Field) Type() Type           { return f.typ }
+func (f *Field) Default() Value       { return f.defaultValue }
+func (f *Field) HasDefault() bool     { return f.defaultValue != nil }
+func (f *Field) Decl() *ast.FieldDecl { return f.decl }
+
+// Doc returns the text of the doc comment of the field, or
+// the argument of its @doc annotation if it has no doc
+// comment.
+func (f *Field) Doc() string { return docText(f.doc, f.annotations) }
+
 type ConceptTree struct {
 	root     *Concept
 	concepts map[string]*Concept
+	enums    map[string]*Enum
+	files    map[string]*ast.File // every file resolved, by cleaned name
+	builtin  string               // the directory of the builtin library
 }
 
 func NewConceptTree() *ConceptTree {
-	c := &Concept{nil, make([]*Concept, 0)}
-	return &ConceptTree{c, map[string]*Concept{"Concept": c}}
+	c := &Concept{parent: nil, children: make([]*Concept, 0), name: rootConceptName}
+	return &ConceptTree{c, map[string]*Concept{rootConceptName: c}, make(map[string]*Enum), make(map[string]*ast.File), ""}
+}
+
+// SetBuiltin sets the directory of the builtin library, which
+// imports starting with builtin/ are relative to. It must be
+// named as the resolved files in it are, e.g. both relative
+// to the same directory.
+func (tree *ConceptTree) SetBuiltin(dir string) {
+	tree.builtin = dir
+}
+
+// Builtin returns the directory of the builtin library set
+// with SetBuiltin, or "" if there is none.
+func (tree *ConceptTree) Builtin() string { return tree.builtin }
+
+// Root returns the concept every other concept descends from.
+func (tree *ConceptTree) Root() *Concept {
+	return tree.root
+}
+
+// Lookup returns the concept with the given qualified name,
+// such as scrum.Issue, or nil if there is no such concept.
+// Concepts of files without a package clause are looked up
+// by their name alone.
+func (tree *ConceptTree) Lookup(name string) *Concept {
+	return tree.concepts[name]
+}
+
+// Concepts returns every concept in the tree, sorted by
+// qualified name.
+func (tree *ConceptTree) Concepts() []*Concept {
+	concepts := make([]*Concept, 0, len(tree.concepts))
+	for _, c := range tree.concepts {
+		concepts = append(concepts, c)
+	}
+
+	sort.Slice(concepts, func(i, j int) bool {
+		return concepts[i].QualifiedName() < concepts[j].QualifiedName()
+	})
+	return concepts
+}
+
+// returns name in package pkg, as it is referred to from
+// other packages
+func qualify(pkg string, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
 }
@@ -0,0 +1,13 @@
+package concept_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestConcept(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Concept Suite")
+}
@@ -0,0 +1,88 @@
+package concept
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Describe returns the concept in meme syntax with every
+// field it contains, inherited ones included and fully
+// instantiated, and their annotations and doc comments.
+// Inherited fields are marked with the concept that declares
+// them, e.g.
+//
+//	// a list of things to do
+//	concept TodoList extends TypedList<TodoListItem> {
+//		required elements [TodoListItem] // from TypedList
+//	}
+func (c *Concept) Describe() string {
+	var buf bytes.Buffer
+
+	writeDoc(&buf, c.doc, "")
+	fmt.Fprintf(&buf, "%sconcept %s", annotationPrefix(c.annotations), c.QualifiedName())
+	if c.IsGeneric() {
+		params := make([]string, len(c.typeParams))
+		for i, param := range c.typeParams {
+			params[i] = param.Name
+		}
+		fmt.Fprintf(&buf, "<%s>", strings.Join(params, ", "))
+	}
+	if c.extends != nil && !isRoot(c.extends) {
+		fmt.Fprintf(&buf, " extends %s", c.extends)
+	}
+
+	if len(c.allFields) == 0 {
+		buf.WriteString(" {}")
+		return buf.String()
+	}
+
+	buf.WriteString(" {\n")
+	for _, f := range c.allFields {
+		writeDoc(&buf, f.doc, "\t")
+		fmt.Fprintf(&buf, "\t%s", describeField(f))
+		if f.owner != c {
+			fmt.Fprintf(&buf, " // from %s", f.owner)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+
+	return buf.String()
+}
+
+// returns the field as it is declared, with its annotations
+// and its default value
+func describeField(f *Field) string {
+	modifier := "optional"
+	if f.required {
+		modifier = "required"
+	}
+
+	s := fmt.Sprintf("%s%s %s %s", annotationPrefix(f.annotations), modifier, f.name, f.typ)
+	if f.HasDefault() {
+		s += fmt.Sprintf(" = %s", FormatValue(f.defaultValue))
+	}
+	return s
+}
+
+// writes every line of doc as a // comment, indented with
+// indent
+func writeDoc(buf *bytes.Buffer, doc string, indent string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(buf, "%s%s\n", indent, strings.TrimRight("// "+line, " "))
+	}
+}
+
+// returns the annotations followed by a space, or "" if
+// there are none
+func annotationPrefix(list []*Annotation) string {
+	var buf bytes.Buffer
+	for _, a := range list {
+		fmt.Fprintf(&buf, "%s ", a)
+	}
+	return buf.String()
+}
@@ -0,0 +1,59 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Doc comments", func() {
+	It("Should carry doc comments into the concept model", func() {
+		tree, err := resolve(parseSources(
+			`/// a unit of work,
+			/// with a title
+			concept Issue {
+				// what the issue is about
+				required title string
+				@doc("how much work it is") optional points integer
+			}
+			// an issue that has to be fixed
+			concept Bug extends Issue {}
+			// the state of an issue
+			enum Status { Open }
+			// an issue that must be closed first
+			relation Blocks from Issue to Issue {
+				// why it blocks
+				optional reason string
+			}`,
+		))
+		Expect(err).NotTo(HaveOccurred())
+
+		issue := tree.Lookup("Issue")
+		Expect(issue.Doc()).To(Equal("a unit of work,\nwith a title"))
+		Expect(issue.LookupField("title").Doc()).To(Equal("what the issue is about"))
+		Expect(issue.LookupField("points").Doc()).To(Equal("how much work it is"))
+		Expect(tree.LookupEnum("Status").Doc()).To(Equal("the state of an issue"))
+		Expect(tree.LookupRelation("Blocks").Doc()).To(Equal("an issue that must be closed first"))
+
+		// inherited fields keep their doc comments
+		bug := tree.Lookup("Bug")
+		Expect(bug.AllFields()[0].Doc()).To(Equal("what the issue is about"))
+		Expect(bug.Describe()).To(Equal(strings.Join([]string{
+			"// an issue that has to be fixed",
+			"concept Bug extends Issue {",
+			"\t// what the issue is about",
+			"\trequired title string // from Issue",
+			"\t@doc(\"how much work it is\") optional points integer // from Issue",
+			"}",
+		}, "\n")))
+		Expect(tree.LookupRelation("Blocks").Describe()).To(Equal(strings.Join([]string{
+			"// an issue that must be closed first",
+			"relation Blocks from many Issue to many Issue {",
+			"\t// why it blocks",
+			"\toptional reason string",
+			"}",
+		}, "\n")))
+		Expect(tree.LookupEnum("Status").Describe()).To(HavePrefix("// the state of an issue\nenum Status {"))
+	})
+})
@@ -0,0 +1,243 @@
+package concept
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// Enum is a closed set of values declared with
+//
+//	enum Status { Todo, InProgress = "in-progress", Done }
+//
+// A member without a value stands for its own name. The
+// values of the members of an enum are either all strings
+// or all integers.
+type Enum struct {
+	name        string
+	pkg         string // the package of the enum, "" if it has none
+	doc         string // the text of the doc comment
+	members     []*EnumMember
+	annotations []*Annotation
+	decl        *ast.EnumDecl
+	file        string // the file decl was read from
+}
+
+func (e *Enum) Name() string               { return e.name }
+func (e *Enum) Package() string            { return e.pkg }
+func (e *Enum) Members() []*EnumMember     { return e.members }
+func (e *Enum) Annotations() []*Annotation { return e.annotations }
+func (e *Enum) Decl() *ast.EnumDecl        { return e.decl }
+func (e *Enum) File() string               { return e.file }
+func (e *Enum) String() string             { return e.QualifiedName() }
+
+// QualifiedName returns the name of the enum prefixed with
+// its package, as in scrum.Status.
+func (e *Enum) QualifiedName() string { return qualify(e.pkg, e.name) }
+
+// Doc returns the text of the doc comment of the enum, or
+// the argument of its @doc annotation if it has no doc
+// comment.
+func (e *Enum) Doc() string { return docText(e.doc, e.annotations) }
+
+// Annotation returns the first annotation of the enum with
+// the given name, or nil if there is none.
+func (e *Enum) Annotation(name string) *Annotation {
+	return findAnnotation(e.annotations, name)
+}
+
+// Member returns the member with the given name, or nil if
+// there is no such member.
+func (e *Enum) Member(name string) *EnumMember {
+	for _, m := range e.members {
+		if m.name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// EnumMember is a member of an enum.
+type EnumMember struct {
+	enum  *Enum
+	name  string
+	value Value  // a string or an int64
+	doc   string // the text of the doc comment
+	decl  *ast.EnumMember
+}
+
+func (m *EnumMember) Enum() *Enum           { return m.enum }
+func (m *EnumMember) Name() string          { return m.name }
+func (m *EnumMember) Value() Value          { return m.value }
+func (m *EnumMember) Doc() string           { return m.doc }
+func (m *EnumMember) Decl() *ast.EnumMember { return m.decl }
+
+// EnumType is a reference to an enum.
+type EnumType struct {
+	Enum *Enum
+}
+
+func (t *EnumType) typeNode()      {}
+func (t *EnumType) String() string { return t.Enum.QualifiedName() }
+
+// LookupEnum returns the enum with the given qualified
+// name, or nil if there is no such enum.
+func (tree *ConceptTree) LookupEnum(name string) *Enum {
+	return tree.enums[name]
+}
+
+// Enums returns every enum in the tree, sorted by qualified
+// name.
+func (tree *ConceptTree) Enums() []*Enum {
+	enums := make([]*Enum, 0, len(tree.enums))
+	for _, e := range tree.enums {
+		enums = append(enums, e)
+	}
+
+	sort.Slice(enums, func(i, j int) bool {
+		return enums[i].QualifiedName() < enums[j].QualifiedName()
+	})
+	return enums
+}
+
+// Describe returns the enum in meme syntax, with its doc
+// comment and the value and the doc comment of every member.
+func (e *Enum) Describe() string {
+	var buf bytes.Buffer
+
+	writeDoc(&buf, e.doc, "")
+	fmt.Fprintf(&buf, "%senum %s", annotationPrefix(e.annotations), e.QualifiedName())
+	if len(e.members) == 0 {
+		buf.WriteString(" {}")
+		return buf.String()
+	}
+
+	buf.WriteString(" {\n")
+	for _, m := range e.members {
+		writeDoc(&buf, m.doc, "\t")
+		fmt.Fprintf(&buf, "\t%s = %s,\n", m.name, FormatValue(m.value))
+	}
+	buf.WriteString("}")
+
+	return buf.String()
+}
+
+// registers every enum declaration under its qualified
+// name. Enums share their names with concepts.
+func (r *resolver) declareEnums(files []*ast.File) []*Enum {
+	declared := make([]*Enum, 0)
+
+	for _, f := range files {
+		for _, d := range f.Enums {
+			name := qualify(f.PackageName(), d.Name.Name)
+			if previous := r.declaration(name); previous != nil {
+				r.errorf(d.Name, diag.CodeRedeclaredEnum, "enum `%s` redeclared", name).
+					WithSecondary(diag.SpanOf(previous), "previous declaration of `%s`", name)
+				continue
+			}
+
+			e := &Enum{name: d.Name.Name, pkg: f.PackageName(), doc: d.Doc.Text(), decl: d, file: f.Name}
+			r.tree.enums[name] = e
+			declared = append(declared, e)
+		}
+	}
+
+	return declared
+}
+
+// returns the name of the concept or enum declared with the
+// given qualified name, or nil if there is none
+func (r *resolver) declaration(name string) diag.Node {
+	if c, ok := r.tree.concepts[name]; ok && c.decl != nil {
+		return c.decl.Name
+	}
+	if e, ok := r.tree.enums[name]; ok {
+		return e.decl.Name
+	}
+	return nil
+}
+
+// resolves the annotations and the members of enum e
+func (r *resolver) resolveEnum(e *Enum) {
+	e.annotations = r.resolveAnnotations(e.decl.Annotations, func(a *Annotation, b builtin) bool {
+		if !b.onConcept {
+			r.errorf(a.decl, diag.CodeMisplacedAnnotation, "@%s cannot be used on enum `%s`", a.Name, e).
+				WithNote("@%s applies to %s", a.Name, b.target)
+			return false
+		}
+		return true
+	})
+
+	// the kind of the first value decides the kind of all
+	kind := String
+	for _, d := range e.decl.Members {
+		if lit, ok := d.Value.(*ast.BasicLit); ok && lit.Kind == token.TokenIntegerLiteral {
+			kind = Integer
+		}
+		if d.Value != nil {
+			break
+		}
+	}
+
+	for _, d := range e.decl.Members {
+		if previous := e.Member(d.Name.Name); previous != nil {
+			r.errorf(d.Name, diag.CodeRedeclaredMember, "member `%s` redeclared in enum `%s`", d.Name.Name, e).
+				WithSecondary(diag.SpanOf(previous.decl.Name), "previous declaration of `%s`", d.Name.Name)
+			continue
+		}
+
+		m := &EnumMember{enum: e, name: d.Name.Name, value: d.Name.Name, doc: d.Doc.Text(), decl: d}
+		if !r.resolveMemberValue(m, kind) {
+			continue
+		}
+
+		for _, other := range e.members {
+			if other.value == m.value {
+				r.errorf(d, diag.CodeRedeclaredMember, "member `%s` of enum `%s` has the same value as `%s`: %s",
+					m.name, e, other.name, FormatValue(m.value)).
+					WithSecondary(diag.SpanOf(other.decl), "`%s` declared here", other.name)
+				break
+			}
+		}
+		e.members = append(e.members, m)
+	}
+}
+
+// resolves the value of member m and checks that it is of
+// the kind of the values of its enum
+func (r *resolver) resolveMemberValue(m *EnumMember, kind PrimitiveKind) bool {
+	d := m.decl
+	if d.Value == nil {
+		if kind != String {
+			r.errorf(d, diag.CodeInvalidEnumValue, "member `%s` of enum `%s` needs a value: the values of its members are integers",
+				m.name, m.enum)
+			return false
+		}
+		return true
+	}
+
+	lit, ok := d.Value.(*ast.BasicLit)
+	if !ok || (lit.Kind != token.TokenStringLiteral && lit.Kind != token.TokenIntegerLiteral) {
+		r.errorf(d.Value, diag.CodeInvalidEnumValue, "invalid value for member `%s` of enum `%s`: %s is not a string or an integer literal",
+			m.name, m.enum, exprString(d.Value))
+		return false
+	}
+
+	if literalKind[lit.Kind] != kind {
+		r.errorf(d.Value, diag.CodeInvalidEnumValue, "invalid value for member `%s` of enum `%s`: the values of its members are %ss",
+			m.name, m.enum, kind)
+		return false
+	}
+
+	v, mismatch := basicValue(lit)
+	if mismatch != nil {
+		r.errorf(d.Value, diag.CodeInvalidEnumValue, "invalid value for member `%s` of enum `%s`: %s", m.name, m.enum, mismatch.message)
+		return false
+	}
+	m.value = v
+	return true
+}
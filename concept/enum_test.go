@@ -0,0 +1,121 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Enums", func() {
+	Context("Resolving valid enums", func() {
+		It("Should resolve members, values and doc comments", func() {
+			tree, err := resolve(parseSources(
+				`@since("2.0")
+				enum Status {
+					// not started yet
+					Todo,
+					InProgress = "in-progress",
+					Done,
+				}
+				enum Priority { Low = 1, High = 0x10 }`,
+				`import "a.meme"
+
+				concept Task {
+					required status Status
+					optional priority Priority = High
+					optional history [Status] = [Todo, InProgress]
+				}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			status := tree.LookupEnum("Status")
+			Expect(status.Members()).To(HaveLen(3))
+			Expect(status.Member("Todo").Doc()).To(Equal("not started yet"))
+			Expect(status.Member("Todo").Value()).To(Equal("Todo"))
+			Expect(status.Member("InProgress").Value()).To(Equal("in-progress"))
+			Expect(status.Annotation("since").Args).To(Equal([]Value{"2.0"}))
+			Expect(tree.Enums()).To(HaveLen(2))
+			Expect(tree.Lookup("Status")).To(BeNil())
+
+			task := tree.Lookup("Task")
+			Expect(task.LookupField("status").Type()).To(Equal(&EnumType{status}))
+			priority := tree.LookupEnum("Priority")
+			Expect(task.LookupField("priority").Default()).To(BeIdenticalTo(priority.Member("High")))
+			Expect(task.LookupField("history").Default()).To(Equal(ListValue{status.Member("Todo"), status.Member("InProgress")}))
+			Expect(task.Describe()).To(ContainSubstring("optional priority Priority = High\n"))
+			Expect(task.Describe()).To(ContainSubstring("optional history [Status] = [Todo, InProgress]\n"))
+
+			Expect(status.Describe()).To(Equal(
+				"@since(\"2.0\") enum Status {\n" +
+					"\t// not started yet\n" +
+					"\tTodo = \"Todo\",\n" +
+					"\tInProgress = \"in-progress\",\n" +
+					"\tDone = \"Done\",\n" +
+					"}"))
+		})
+
+		It("Should only make an enum a subtype of itself", func() {
+			tree, err := resolve(parseSources(`enum A { X } enum B { X }`))
+			Expect(err).NotTo(HaveOccurred())
+
+			a, b := &EnumType{tree.LookupEnum("A")}, &EnumType{tree.LookupEnum("B")}
+			Expect(IsSubtype(a, a)).To(BeTrue())
+			Expect(IsSubtype(a, b)).To(BeFalse())
+			Expect(IsSubtype(a, &PrimitiveType{String})).To(BeFalse())
+			Expect(IsSubtype(a, &ConceptType{Concept: tree.Root()})).To(BeTrue())
+		})
+	})
+
+	Context("Checking enums", func() {
+		It("Should report redeclared names, members and values", func() {
+			tree, err := resolve(parseSources(
+				`enum Status { Todo, Done, Todo, Finished = "Done" }
+				concept Task {}
+				enum Task { X }`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:27: member `Todo` redeclared in enum `Status`",
+				"a.meme:1:33: member `Finished` of enum `Status` has the same value as `Done`: \"Done\"",
+				"a.meme:3:10: enum `Task` redeclared",
+			}, "\n")))
+
+			// enums declared by an earlier call are taken too
+			err = tree.Resolve(fset, parseSources(`concept Status {}`)...)
+			Expect(err).To(MatchError("a.meme:1:9: concept `Status` redeclared"))
+		})
+
+		It("Should report values that are not of the kind of the enum", func() {
+			_, err := resolve(parseSources(
+				`enum Priority { Low = 1, Medium, High = "high", Top = [4] }
+				enum Flag { On = true }`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:26: member `Medium` of enum `Priority` needs a value: the values of its members are integers",
+				"a.meme:1:41: invalid value for member `High` of enum `Priority`: the values of its members are integers",
+				"a.meme:1:55: invalid value for member `Top` of enum `Priority`: [4] is not a string or an integer literal",
+				"a.meme:2:22: invalid value for member `On` of enum `Flag`: true is not a string or an integer literal",
+			}, "\n")))
+		})
+
+		It("Should report misuses of enums and of their members", func() {
+			_, err := resolve(parseSources(
+				`@min(1) enum Status { Todo }
+				concept Task extends Status {
+					optional status Status = Blocked
+					optional other string = Todo
+					optional any Concept = Todo
+					optional list Status<integer>
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:1: @min cannot be used on enum `Status`",
+				"a.meme:2:26: concept `Task` cannot extend enum `Status`",
+				"a.meme:3:31: invalid default value for field `status`: `Blocked` is not a member of enum `Status`",
+				"a.meme:4:30: invalid default value for field `other`: cannot use Todo as a value of type `string`",
+				"a.meme:5:29: invalid default value for field `any`: cannot use Todo where no enum is expected",
+				"a.meme:6:20: wrong number of type arguments for `Status`: expected 0, found 1",
+			}, "\n")))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generics", func() {
+	Context("Checking the number of type arguments", func() {
+		It("Should reject too many type arguments", func() {
+			_, err := resolve(parseSources(
+				`concept Box<T> {}
+				 concept A { required b Box<string, integer> }`,
+			))
+			Expect(err).To(MatchError("a.meme:2:29: wrong number of type arguments for `Box`: expected 1, found 2"))
+		})
+
+		It("Should reject a generic concept without type arguments", func() {
+			_, err := resolve(parseSources(
+				`concept A extends Box {}
+				 concept Box<T> {}`,
+			))
+			Expect(err).To(MatchError("a.meme:1:19: wrong number of type arguments for `Box`: expected 1, found 0"))
+		})
+
+		It("Should reject type arguments to a concept that is not generic", func() {
+			_, err := resolve(parseSources(
+				`concept A { required b [B<string>] }
+				 concept B {}`,
+			))
+			Expect(err).To(MatchError("a.meme:1:25: wrong number of type arguments for `B`: expected 0, found 1"))
+		})
+
+		It("Should check nested type arguments", func() {
+			_, err := resolve(parseSources(
+				`concept Box<T> {}
+				 concept A { required b Box<Box<Box>> }`,
+			))
+			Expect(err).To(MatchError("a.meme:2:37: wrong number of type arguments for `Box`: expected 1, found 0"))
+		})
+	})
+
+	Context("Checking type variables", func() {
+		It("Should reject undeclared type variables", func() {
+			_, err := resolve(parseSources(
+				`concept Pair extends Map<K, V> {}
+				 concept Map {}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:22: wrong number of type arguments for `Map`: expected 0, found 2",
+				"a.meme:1:26: undefined name `K`: not a concept, an enum or a type parameter of `Pair`",
+				"a.meme:1:29: undefined name `V`: not a concept, an enum or a type parameter of `Pair`",
+			}, "\n")))
+		})
+
+		It("Should not leak type variables into other concepts", func() {
+			_, err := resolve(parseSources(
+				`concept Box<T> { required item T }
+				 concept A { required item T }`,
+			))
+			Expect(err).To(MatchError("a.meme:2:32: undefined name `T`: not a concept, an enum or a type parameter of `A`"))
+		})
+
+		It("Should reject type arguments to type variables", func() {
+			_, err := resolve(parseSources(`concept Box<T> { required item T<string> }`))
+			Expect(err).To(MatchError("a.meme:1:32: type parameter `T` cannot have type arguments"))
+		})
+	})
+
+	Context("Instantiating generic concepts", func() {
+		It("Should substitute through several levels of inheritance", func() {
+			tree, err := resolve(parseSources(
+				`concept Pair<K, V> { required key K  required value V }
+				 concept Entry<V> extends Pair<string, [V]> { optional next Entry<V> }
+				 concept Count extends Entry<integer> {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldStrings(tree.Lookup("Count").AllFields())).To(Equal([]string{
+				"required key string",
+				"required value [integer]",
+				"optional next Entry<integer>",
+			}))
+		})
+
+		It("Should instantiate the fields of a concept type", func() {
+			tree, err := resolve(parseSources(
+				`concept Pair<K, V> { required key K  required value V }
+				 concept A { required pair Pair<string, A> }`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			pair := tree.Lookup("A").LookupField("pair").Type().(*ConceptType)
+			Expect(fieldStrings(pair.Fields())).To(Equal([]string{
+				"required key string",
+				"required value A",
+			}))
+		})
+
+		It("Should check redeclared fields against the instantiated type", func() {
+			_, err := resolve(parseSources(
+				`concept Box<T> { optional item T }
+				 concept StringBox extends Box<string> { required item integer }`,
+			))
+			Expect(err).To(MatchError("a.meme:2:60: field `item` of `StringBox` has type `integer`, " +
+				"which is not a subtype of `string` declared in `Box`"))
+		})
+	})
+
+	Context("Describing concepts", func() {
+		It("Should describe the fully instantiated fields of TodoList", func() {
+			tree, err := resolve(parseDirs("../builtin", "../examples/todolist"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.Lookup("TodoList").Describe()).To(Equal(
+				"concept TodoList extends TypedList<TodoListItem> {\n" +
+					"\trequired elements [TodoListItem] // from TypedList\n" +
+					"}"))
+		})
+
+		It("Should describe generic concepts and concepts without fields", func() {
+			tree, err := resolve(parseDirs("../builtin"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.Lookup("TypedMap").Describe()).To(Equal(
+				"concept TypedMap<K, V> extends Map {\n" +
+					"\trequired elements TypedList<(K, V)>\n" +
+					"}"))
+			Expect(tree.Root().Describe()).To(Equal("concept Concept {}"))
+		})
+	})
+})
@@ -0,0 +1,213 @@
+package concept
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/loader"
+)
+
+// A file can refer to the concepts and enums it declares,
+// to the ones declared in the files it imports and to the
+// root concept. Imports are not transitive. An import names
+// a file or a directory, whose .meme files are all
+// imported, relative to the directory of the importing file,
+// or to the builtin library for paths that start with
+// builtin/; see ConceptTree.SetBuiltin.
+//
+// A file that starts with `package scrum` declares its
+// concepts and enums in package scrum, where they are known
+// as scrum.Issue and so on. Unqualified names are looked up
+// in the package of the file, then among the declarations of
+// files without a package. Qualified names are looked up in
+// the package of an imported file, or in the package of the
+// files imported under an alias:
+//
+//	import tasks "../scrum"
+//	concept Board { required issues [tasks.Issue] }
+
+// registers the files and checks that each of their imports
+// matches at least one file known to the tree, and that
+// every alias stands for a single package
+func (r *resolver) declareFiles(files []*ast.File) {
+	for _, f := range files {
+		r.tree.files[filepath.Clean(f.Name)] = f
+	}
+
+	for _, f := range files {
+		aliases := make(map[string]*ast.ImportDecl)
+
+		for _, d := range f.Imports {
+			packages := r.importedPackages(f, d)
+			if len(packages) == 0 {
+				r.errorf(d.Path, diag.CodeImportNotFound, "import %s matches no file: %s was not loaded", d.Path.Value, loader.Target(d, f.Name, r.tree.builtin))
+				continue
+			}
+			if d.Name == nil {
+				continue
+			}
+
+			if previous, ok := aliases[d.Name.Name]; ok {
+				r.errorf(d.Name, diag.CodeAmbiguousImport, "import alias `%s` redeclared", d.Name.Name).
+					WithSecondary(diag.SpanOf(previous.Name), "previous declaration of `%s`", d.Name.Name)
+				continue
+			}
+			aliases[d.Name.Name] = d
+
+			if len(packages) > 1 {
+				r.errorf(d.Name, diag.CodeAmbiguousImport, "import alias `%s` stands for more than one package: %s",
+					d.Name.Name, packageList(packages))
+			}
+		}
+	}
+}
+
+// returns the sorted names of the packages of the files
+// imported by d, a declaration of file f
+func (r *resolver) importedPackages(f *ast.File, d *ast.ImportDecl) []string {
+	target := loader.Target(d, f.Name, r.tree.builtin)
+	seen := make(map[string]bool)
+	packages := make([]string, 0)
+
+	for name, imported := range r.tree.files {
+		if imports(target, name) && !seen[imported.PackageName()] {
+			seen[imported.PackageName()] = true
+			packages = append(packages, imported.PackageName())
+		}
+	}
+
+	sort.Strings(packages)
+	return packages
+}
+
+// returns the package qualifier q stands for in the file
+// the concept c is declared in: the package of the files
+// imported under the alias q, or package q itself if the
+// file is part of it or imports one of its files
+func (r *resolver) qualifier(c *Concept, q string) (string, bool) {
+	f, ok := r.tree.files[filepath.Clean(c.file)]
+	if !ok {
+		return "", false
+	}
+	if f.PackageName() == q {
+		return q, true
+	}
+
+	for _, d := range f.Imports {
+		packages := r.importedPackages(f, d)
+		if d.Name != nil && d.Name.Name == q && len(packages) > 0 {
+			return packages[0], true
+		}
+		if d.Name != nil {
+			continue
+		}
+		for _, pkg := range packages {
+			if pkg == q {
+				return q, true
+			}
+		}
+	}
+	return "", false
+}
+
+// returns the qualified name of the concept or enum e refers
+// to from concept c, reporting an error if there is none
+func (r *resolver) lookup(c *Concept, e *ast.NamedType) (string, bool) {
+	declared := func(name string) bool {
+		_, isConcept := r.tree.concepts[name]
+		_, isEnum := r.tree.enums[name]
+		return isConcept || isEnum
+	}
+
+	if e.Package != nil {
+		pkg, ok := r.qualifier(c, e.Package.Name)
+		if !ok {
+			r.errorf(e.Package, diag.CodeUndefinedPackage, "undefined package `%s`: not the package of %s, nor an imported package or alias",
+				e.Package.Name, c.file)
+			return "", false
+		}
+
+		name := qualify(pkg, e.Name.Name)
+		if !declared(name) {
+			r.errorf(e.Name, diag.CodeUndefinedName, "undefined name `%s`: no concept or enum `%s` in the files imported as `%s`",
+				e.QualifiedName(), e.Name.Name, e.Package.Name)
+			return "", false
+		}
+		return name, true
+	}
+
+	if name := qualify(c.pkg, e.Name.Name); declared(name) {
+		return name, true
+	}
+	if declared(e.Name.Name) {
+		return e.Name.Name, true
+	}
+
+	r.errorf(e.Name, diag.CodeUndefinedName, "undefined name `%s`: not a concept, an enum or a type parameter of `%s`", e.Name.Name, c)
+	return "", false
+}
+
+// reports whether the concepts and enums declared in file
+// to can be referred to from file from
+func (r *resolver) visible(from string, to string) bool {
+	from, to = filepath.Clean(from), filepath.Clean(to)
+	if from == to {
+		return true
+	}
+
+	f, ok := r.tree.files[from]
+	if !ok {
+		return false
+	}
+	for _, d := range f.Imports {
+		if imports(loader.Target(d, from, r.tree.builtin), to) {
+			return true
+		}
+	}
+	return false
+}
+
+// reports an error at e if what it refers to, declared in
+// file declaredIn, cannot be referred to from concept c
+func (r *resolver) checkVisible(c *Concept, e *ast.NamedType, declaredIn string) bool {
+	if r.visible(c.file, declaredIn) {
+		return true
+	}
+
+	path, err := filepath.Rel(filepath.Dir(c.file), declaredIn)
+	if err != nil {
+		path = declaredIn
+	}
+	if r.tree.builtin != "" {
+		if rel, err := filepath.Rel(r.tree.builtin, declaredIn); err == nil && !strings.HasPrefix(rel, "..") {
+			path = loader.BuiltinPrefix + rel
+		}
+	}
+	r.errorf(e.Name, diag.CodeNotImported, "`%s` is declared in %s, which is not imported by %s", e.QualifiedName(), declaredIn, c.file).
+		WithNote("add `import %q` at the top of %s", filepath.ToSlash(path), c.file)
+	return false
+}
+
+// reports whether importing target imports file name: the
+// file itself or a .meme file in the directory target
+func imports(target string, name string) bool {
+	return name == target || filepath.Dir(name) == target && strings.HasSuffix(name, ".meme")
+}
+
+// describes a list of package names, the package of files
+// without a package clause included
+func packageList(packages []string) string {
+	quoted := make([]string, len(packages))
+	for i, pkg := range packages {
+		if pkg == "" {
+			quoted[i] = "files without a package"
+		} else {
+			quoted[i] = fmt.Sprintf("`%s`", pkg)
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
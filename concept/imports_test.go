@@ -0,0 +1,161 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/parser"
+)
+
+// parses the given sources, keyed by file name
+func parseNamed(sources map[string]string) []*ast.File {
+	files := make([]*ast.File, 0, len(sources))
+	for name, src := range sources {
+		f, err := parser.ParseSource(fset, name, src)
+		Expect(err).NotTo(HaveOccurred())
+		files = append(files, f)
+	}
+	return files
+}
+
+var _ = Describe("Imports", func() {
+	It("Should resolve names through imported files and directories", func() {
+		tree, err := resolve(parseNamed(map[string]string{
+			"model/issue.meme":  `enum Status { Open, Closed }`,
+			"model/person.meme": `concept Person { required name string }`,
+			"app/board.meme": `import "../model"
+				concept Board { required owner Person  optional status Status }`,
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree.Lookup("Board").Fields()[0].Type().String()).To(Equal("Person"))
+	})
+
+	It("Should let every file refer to the root concept", func() {
+		_, err := resolve(parseSources(
+			`concept Concept {}`,
+			`concept A { required c Concept }`,
+		))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should report names declared in files that are not imported", func() {
+		_, err := resolve(parseSources(
+			`concept A {}
+			enum E { X }`,
+			`import "c.meme"
+			concept B extends A { optional e E }`,
+			`concept C {}`,
+		))
+		Expect(err).To(MatchError(
+			"b.meme:2:22: `A` is declared in a.meme, which is not imported by b.meme\n" +
+				"b.meme:2:37: `E` is declared in a.meme, which is not imported by b.meme"))
+
+		d := err.(diag.List)[0]
+		Expect(d.Code).To(Equal(diag.CodeNotImported))
+		Expect(d.Notes).To(ConsistOf("add `import \"a.meme\"` at the top of b.meme"))
+	})
+
+	It("Should not make imports transitive", func() {
+		_, err := resolve(parseSources(
+			`concept A {}`,
+			`import "a.meme"
+			concept B extends A {}`,
+			`import "b.meme"
+			concept C extends A {}`,
+		))
+		Expect(err).To(MatchError("c.meme:2:22: `A` is declared in a.meme, which is not imported by c.meme"))
+	})
+
+	It("Should report imports that match no file", func() {
+		_, err := resolve(parseSources(`import "missing.meme"
+			concept A {}`))
+		Expect(err).To(MatchError("a.meme:1:8: import \"missing.meme\" matches no file: missing.meme was not loaded"))
+		Expect(err.(diag.List)[0].Code).To(Equal(diag.CodeImportNotFound))
+	})
+})
+
+var _ = Describe("Packages", func() {
+	It("Should declare names in the package of their file", func() {
+		tree, err := resolve(parseNamed(map[string]string{
+			"scrum/issue.meme": `package scrum
+				enum Status { Open, Closed }
+				concept Issue { required status Status }`,
+			"support/issue.meme": `package support
+				concept Issue { required ticket string }`,
+			"board.meme": `package board
+				import "scrum"
+				import help "support"
+				concept Board {
+					required issues [scrum.Issue]
+					required tickets [help.Issue]
+					optional status scrum.Status = Open
+				}`,
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		issue := tree.Lookup("scrum.Issue")
+		Expect(issue.Name()).To(Equal("Issue"))
+		Expect(issue.Package()).To(Equal("scrum"))
+		Expect(issue.String()).To(Equal("scrum.Issue"))
+		Expect(tree.Lookup("support.Issue")).NotTo(BeNil())
+		Expect(tree.Lookup("Issue")).To(BeNil())
+		Expect(tree.LookupEnum("scrum.Status")).NotTo(BeNil())
+
+		fields := tree.Lookup("board.Board").Fields()
+		Expect(fields[0].Type().String()).To(Equal("[scrum.Issue]"))
+		Expect(fields[1].Type().String()).To(Equal("[support.Issue]"))
+		Expect(fields[2].Type().String()).To(Equal("scrum.Status"))
+	})
+
+	It("Should look up unqualified names in the package, then among files without one", func() {
+		tree, err := resolve(parseNamed(map[string]string{
+			"list.meme": `concept List<T> {}`,
+			"task.meme": `package tasks
+				import "list.meme"
+				concept Task {}
+				concept Tasks extends List<Task> {}`,
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree.Lookup("tasks.Tasks").Describe()).To(HavePrefix("concept tasks.Tasks extends List<tasks.Task>"))
+	})
+
+	It("Should report unknown qualifiers and names with qualified names", func() {
+		_, err := resolve(parseNamed(map[string]string{
+			"scrum.meme": `package scrum
+				concept Issue {}`,
+			"board.meme": `package board
+				import "scrum.meme"
+				concept Board extends scrum.Isue {
+					required issues [scrm.Issue]
+					required board Board
+					required other board.Board
+				}
+				concept Board {}`,
+		}))
+		Expect(err).To(MatchError(strings.Join([]string{
+			"board.meme:3:33: undefined name `scrum.Isue`: no concept or enum `Isue` in the files imported as `scrum`",
+			"board.meme:4:23: undefined package `scrm`: not the package of board.meme, nor an imported package or alias",
+			"board.meme:8:13: concept `board.Board` redeclared",
+		}, "\n")))
+	})
+
+	It("Should report aliases that are redeclared or stand for several packages", func() {
+		_, err := resolve(parseNamed(map[string]string{
+			"lib/a.meme": `package a`,
+			"lib/b.meme": `package b`,
+			"c.meme":     `package c`,
+			"main.meme": `import x "lib"
+				import y "c.meme"
+				import y "c.meme"`,
+		}))
+		Expect(err).To(MatchError(strings.Join([]string{
+			"main.meme:1:8: import alias `x` stands for more than one package: `a`, `b`",
+			"main.meme:3:12: import alias `y` redeclared",
+		}, "\n")))
+		Expect(err.(diag.List)[0].Code).To(Equal(diag.CodeAmbiguousImport))
+	})
+})
@@ -0,0 +1,160 @@
+package concept
+
+import (
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+// AllFields returns the fields of c including the ones it
+// inherits, with the type arguments of its extends clause
+// substituted into them. Inherited fields come first, in the
+// order of the ancestor that declares them. A field that c
+// redeclares replaces the inherited one in place.
+func (c *Concept) AllFields() []*Field {
+	return c.allFields
+}
+
+// LookupField returns the field of c, declared or inherited,
+// with the given name, or nil if there is no such field.
+func (c *Concept) LookupField(name string) *Field {
+	for _, f := range c.allFields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// breaks every inheritance cycle among the declared
+// concepts by re-parenting the concept that closes the
+// cycle under the root, reporting the cycle once
+func (r *resolver) checkCycles(declared []*Concept) {
+	done := make(map[*Concept]bool)
+
+	for _, c := range declared {
+		path := make([]*Concept, 0)
+		onPath := make(map[*Concept]int)
+
+		for n := c; n != nil && !done[n]; n = n.parent {
+			if i, ok := onPath[n]; ok {
+				r.reportCycle(path[i:])
+				last := path[len(path)-1]
+				r.reparent(last, r.tree.root)
+				break
+			}
+			onPath[n] = len(path)
+			path = append(path, n)
+		}
+
+		for _, n := range path {
+			done[n] = true
+		}
+	}
+}
+
+func (r *resolver) reportCycle(cycle []*Concept) {
+	names := make([]string, 0, len(cycle)+1)
+	for _, c := range cycle {
+		names = append(names, c.QualifiedName())
+	}
+	names = append(names, cycle[0].QualifiedName())
+
+	first := cycle[0]
+	d := r.errorf(first.decl.Extends, diag.CodeInheritanceCycle,
+		"inheritance cycle: %s", strings.Join(names, " -> ")).
+		WithLabel("`%s` extends `%s`", first, first.parent)
+	for _, c := range cycle[1:] {
+		d.WithSecondary(diag.SpanOf(c.decl.Extends), "`%s` extends `%s`", c, c.parent)
+	}
+}
+
+func (r *resolver) reparent(c *Concept, parent *Concept) {
+	old := c.parent
+	for i, child := range old.children {
+		if child == c {
+			old.children = append(old.children[:i], old.children[i+1:]...)
+			break
+		}
+	}
+
+	c.parent = parent
+	c.extends = &ConceptType{Concept: parent}
+	parent.children = append(parent.children, c)
+}
+
+// computes the fields of c including inherited ones and
+// checks that redeclared fields only narrow the fields they
+// redeclare: optional may become required, and the type may
+// become a subtype of the inherited type
+func (r *resolver) inheritFields(c *Concept, done map[*Concept]bool) {
+	if done[c] {
+		return
+	}
+	done[c] = true
+
+	all := make([]*Field, 0)
+	if c.parent != nil {
+		r.inheritFields(c.parent, done)
+		all = append(all, c.extends.Fields()...)
+	}
+
+	for _, f := range c.fields {
+		replaced := false
+		for i, inherited := range all {
+			if inherited.name == f.name {
+				r.checkRedeclaration(c, f, inherited)
+				all[i] = f
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			all = append(all, f)
+		}
+	}
+
+	c.allFields = all
+}
+
+func (r *resolver) checkRedeclaration(c *Concept, f *Field, inherited *Field) {
+	if inherited.required && !f.required {
+		r.errorf(f.decl, diag.CodeWidenedField, "field `%s` is required in `%s` and cannot be made optional in `%s`",
+			f.name, inherited.owner, c).
+			WithSecondary(diag.SpanOf(inherited.decl), "declared required here")
+	}
+
+	if f.typ == nil || inherited.typ == nil {
+		// already reported
+		return
+	}
+
+	if !IsSubtype(f.typ, inherited.typ) {
+		r.errorf(f.decl.Type, diag.CodeIncompatibleField, "field `%s` of `%s` has type `%s`, which is not a subtype of `%s` declared in `%s`",
+			f.name, c, f.typ, inherited.typ, inherited.owner).
+			WithSecondary(diag.SpanOf(inherited.decl.Type), "declared as `%s` here", inherited.typ)
+	}
+}
+
+// Fields returns the fields of the concept instantiated
+// with the type arguments of t, e.g. the fields of
+// TypedList<TodoListItem> are the fields of TypedList with
+// every T replaced by TodoListItem.
+func (t *ConceptType) Fields() []*Field {
+	bindings := t.bindings()
+	fields := make([]*Field, len(t.Concept.allFields))
+	for i, f := range t.Concept.allFields {
+		fields[i] = &Field{
+			owner:        f.owner,
+			name:         f.name,
+			required:     f.required,
+			typ:          substitute(f.typ, bindings),
+			defaultValue: f.defaultValue,
+			doc:          f.doc,
+			annotations:  f.annotations,
+			decl:         f.decl,
+		}
+	}
+	return fields
+}
@@ -0,0 +1,192 @@
+package concept
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+func fieldStrings(fields []*Field) []string {
+	s := make([]string, len(fields))
+	for i, f := range fields {
+		modifier := "optional"
+		if f.Required() {
+			modifier = "required"
+		}
+		s[i] = modifier + " " + f.Name() + " " + f.Type().String()
+	}
+	return s
+}
+
+var _ = Describe("Inheritance", func() {
+	Context("Detecting cycles", func() {
+		It("Should report a cycle between two concepts with a trace", func() {
+			_, err := resolve(parseSources(
+				"import \"b.meme\"\nconcept A extends B {}",
+				"import \"a.meme\"\nconcept B extends A {}",
+			))
+			Expect(err).To(MatchError("a.meme:2:19: inheritance cycle: A -> B -> A"))
+
+			d := err.(diag.List)[0]
+			Expect(d.Code).To(Equal(diag.CodeInheritanceCycle))
+			Expect(d.Primary.Message).To(Equal("`A` extends `B`"))
+			Expect(d.Secondary).To(HaveLen(1))
+			Expect(fset.Position(d.Secondary[0].Span.Pos).Filename).To(Equal("b.meme"))
+			Expect(d.Secondary[0].Message).To(Equal("`B` extends `A`"))
+		})
+
+		It("Should report a concept extending itself", func() {
+			tree, err := resolve(parseSources(`concept A extends A {}`))
+			Expect(err).To(MatchError("a.meme:1:19: inheritance cycle: A -> A"))
+			Expect(tree.Lookup("A").Parent()).To(Equal(tree.Root()))
+		})
+
+		It("Should report a cycle reached from outside only once", func() {
+			tree, err := resolve(parseSources(
+				`concept C extends A {}
+				 concept A extends B {}
+				 concept B extends A {}`,
+			))
+			Expect(err.(diag.List)).To(HaveLen(1))
+			Expect(tree.Lookup("C").IsA(tree.Root())).To(BeTrue())
+		})
+	})
+
+	Context("Inheriting fields", func() {
+		It("Should inherit fields down the tree", func() {
+			tree, err := resolve(parseSources(
+				`concept A { required a string }
+				 concept B extends A { optional b integer }
+				 concept C extends B { required c boolean }`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			c := tree.Lookup("C")
+			Expect(fieldStrings(c.AllFields())).To(Equal([]string{
+				"required a string",
+				"optional b integer",
+				"required c boolean",
+			}))
+			Expect(c.Fields()).To(HaveLen(1))
+			Expect(c.LookupField("a").Owner()).To(Equal(tree.Lookup("A")))
+		})
+
+		It("Should substitute the type arguments of the extends clause", func() {
+			tree, err := resolve(parseSources(
+				`concept Box<T> { required item T }
+				 concept Crate extends Box<[string]> {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldStrings(tree.Lookup("Crate").AllFields())).To(Equal([]string{
+				"required item [string]",
+			}))
+		})
+
+		It("Should allow narrowing optional to required", func() {
+			tree, err := resolve(parseSources(
+				`concept A { optional a string }
+				 concept B extends A { required a string }`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.Lookup("B").LookupField("a").Required()).To(BeTrue())
+		})
+
+		It("Should allow narrowing a type to a subtype", func() {
+			tree, err := resolve(parseSources(
+				`concept Animal {}
+				 concept Dog extends Animal {}
+				 concept Owner { optional pets [oneof(Animal, string)] }
+				 concept DogOwner extends Owner { optional pets [Dog] }`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldStrings(tree.Lookup("DogOwner").AllFields())).To(Equal([]string{
+				"optional pets [Dog]",
+			}))
+		})
+
+		It("Should reject widening required to optional", func() {
+			_, err := resolve(parseSources(
+				`concept A { required a string }
+				 concept B extends A {
+					optional a string
+				 }`,
+			))
+			Expect(err).To(MatchError("a.meme:3:6: field `a` is required in `A` and cannot be made optional in `B`"))
+		})
+
+		It("Should reject a type that is not a subtype", func() {
+			_, err := resolve(parseSources(
+				`concept Animal {}
+				 concept Dog extends Animal {}
+				 concept A { required a Dog }
+				 concept B extends A { required a Animal }`,
+			))
+			Expect(err).To(MatchError("a.meme:4:39: field `a` of `B` has type `Animal`, which is not a subtype of `Dog` declared in `A`"))
+		})
+	})
+
+	Context("Inheriting in the bundled schemas", func() {
+		It("Should give Deadline the fields of Time", func() {
+			tree, _ := resolve(parseDirs("../builtin", "../examples/scrum_board"))
+			Expect(fieldStrings(tree.Lookup("scrum.Deadline").AllFields())).To(Equal([]string{
+				"required time string",
+			}))
+		})
+
+		It("Should give TodoList the instantiated fields of TypedList", func() {
+			tree, _ := resolve(parseDirs("../builtin", "../examples/todolist"))
+			Expect(fieldStrings(tree.Lookup("TodoList").AllFields())).To(Equal([]string{
+				"required elements [TodoListItem]",
+			}))
+		})
+	})
+})
+
+var _ = Describe("IsSubtype", func() {
+	var tree *ConceptTree
+
+	BeforeEach(func() {
+		var err error
+		tree, err = resolve(parseSources(
+			`concept Animal {}
+			 concept Dog extends Animal {}
+			 concept Box<T> {}
+			 concept DogBox extends Box<Dog> {}`,
+		))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	named := func(name string, args ...Type) Type {
+		return &ConceptType{Concept: tree.Lookup(name), Args: args}
+	}
+	str := &PrimitiveType{String}
+	integer := &PrimitiveType{Integer}
+
+	It("Should treat Concept as the top type", func() {
+		Expect(IsSubtype(str, named("Concept"))).To(BeTrue())
+		Expect(IsSubtype(&ListType{integer}, named("Concept"))).To(BeTrue())
+	})
+
+	It("Should follow the concept hierarchy", func() {
+		Expect(IsSubtype(named("Dog"), named("Animal"))).To(BeTrue())
+		Expect(IsSubtype(named("Animal"), named("Dog"))).To(BeFalse())
+	})
+
+	It("Should compare type arguments of ancestors", func() {
+		Expect(IsSubtype(named("DogBox"), named("Box", named("Animal")))).To(BeTrue())
+		Expect(IsSubtype(named("DogBox"), named("Box", str))).To(BeFalse())
+	})
+
+	It("Should handle unions on both sides", func() {
+		Expect(IsSubtype(str, &OneOfType{[]Type{integer, str}})).To(BeTrue())
+		Expect(IsSubtype(&OneOfType{[]Type{integer, str}}, str)).To(BeFalse())
+		Expect(IsSubtype(&AnyOfType{[]Type{named("Dog"), str}}, &OneOfType{[]Type{str, named("Animal")}})).To(BeTrue())
+	})
+
+	It("Should compare lists and tuples element-wise", func() {
+		Expect(IsSubtype(&ListType{named("Dog")}, &ListType{named("Animal")})).To(BeTrue())
+		Expect(IsSubtype(&TupleType{[]Type{str, integer}}, &TupleType{[]Type{str, str}})).To(BeFalse())
+		Expect(IsSubtype(&TupleType{[]Type{str, integer}}, &TupleType{[]Type{str}})).To(BeFalse())
+	})
+})
@@ -0,0 +1,407 @@
+package concept
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+// the name of the concept every relation extends, if it is
+// declared
+const relationConceptName = "Relation"
+
+// Cardinality bounds the number of instances at one end of
+// a relation that an instance at the other end is related to.
+type Cardinality int
+
+const (
+	Many Cardinality = iota
+	One
+)
+
+func (c Cardinality) String() string {
+	if c == One {
+		return "one"
+	}
+	return "many"
+}
+
+// Relation is a relation between two concepts, declared with
+//
+//	relation AssignedTo from many Issue to one User { optional since Time }
+//
+// An instance of the relation is an edge from an instance of
+// the concept at its From end to an instance of the concept
+// at its To end. Here every issue is assigned to at most one
+// user, and a user may be assigned many issues.
+//
+// A relation is also a concept, which has the required fields
+// from and to followed by the fields of its body, so that its
+// instances are checked like those of any other concept. It
+// extends the builtin concept Relation when it is declared.
+//
+// The properties written after the ends of a relation are
+// checked against its ends:
+//
+//	relation BlockedBy from Issue to Issue inverse of Blocks {}
+//	relation RelatedTo from Issue to Issue symmetric {}
+//	relation PartOf from Component to Component transitive acyclic {}
+//
+// The ends of an inverse are those of the other relation
+// swapped. A symmetric relation has the same ends, and a
+// transitive or an acyclic one relates a concept to itself.
+type Relation struct {
+	concept *Concept
+	from    *RelationEnd
+	to      *RelationEnd
+	decl    *ast.RelationDecl
+
+	inverseOf  *Relation // the relation declared with "inverse of"
+	inverse    *Relation // inverseOf, or the relation declared as the inverse of this one
+	symmetric  bool
+	transitive bool
+	acyclic    bool
+}
+
+func (rel *Relation) Name() string            { return rel.concept.name }
+func (rel *Relation) Package() string         { return rel.concept.pkg }
+func (rel *Relation) QualifiedName() string   { return rel.concept.QualifiedName() }
+func (rel *Relation) Concept() *Concept       { return rel.concept }
+func (rel *Relation) From() *RelationEnd      { return rel.from }
+func (rel *Relation) To() *RelationEnd        { return rel.to }
+func (rel *Relation) Decl() *ast.RelationDecl { return rel.decl }
+func (rel *Relation) File() string            { return rel.concept.file }
+func (rel *Relation) String() string          { return rel.QualifiedName() }
+func (rel *Relation) Doc() string             { return rel.concept.Doc() }
+func (rel *Relation) Symmetric() bool         { return rel.symmetric }
+func (rel *Relation) Transitive() bool        { return rel.transitive }
+func (rel *Relation) Acyclic() bool           { return rel.acyclic }
+
+// Inverse returns the inverse of the relation, whether the
+// relation declares it with "inverse of" or is named in the
+// declaration of the inverse. It returns nil if the relation
+// has no inverse.
+func (rel *Relation) Inverse() *Relation { return rel.inverse }
+
+// RelationEnd is one of the ends of a relation.
+type RelationEnd struct {
+	Concept     *ConceptType // nil if it could not be resolved
+	Cardinality Cardinality
+	decl        *ast.RelationEnd
+}
+
+func (e *RelationEnd) Decl() *ast.RelationEnd { return e.decl }
+
+func (e *RelationEnd) String() string {
+	if e.Concept == nil {
+		return e.Cardinality.String()
+	}
+	return fmt.Sprintf("%s %s", e.Cardinality, e.Concept)
+}
+
+// Relation returns the relation the concept stands for, or
+// nil if it is not declared as a relation.
+func (c *Concept) Relation() *Relation { return c.relation }
+
+// LookupRelation returns the relation with the given
+// qualified name, or nil if there is no such relation.
+func (tree *ConceptTree) LookupRelation(name string) *Relation {
+	if c, ok := tree.concepts[name]; ok {
+		return c.relation
+	}
+	return nil
+}
+
+// Relations returns every relation in the tree, sorted by
+// qualified name.
+func (tree *ConceptTree) Relations() []*Relation {
+	relations := make([]*Relation, 0)
+	for _, c := range tree.Concepts() {
+		if c.relation != nil {
+			relations = append(relations, c.relation)
+		}
+	}
+	return relations
+}
+
+// RelationsFrom returns the relations an instance of c can
+// be at the From end of: those from c or from one of its
+// ancestors, sorted by qualified name.
+func (tree *ConceptTree) RelationsFrom(c *Concept) []*Relation {
+	return tree.edges(c, (*Relation).From)
+}
+
+// RelationsTo returns the relations an instance of c can be
+// at the To end of: those to c or to one of its ancestors,
+// sorted by qualified name.
+func (tree *ConceptTree) RelationsTo(c *Concept) []*Relation {
+	return tree.edges(c, (*Relation).To)
+}
+
+// returns the relations whose end is c or an ancestor of c
+func (tree *ConceptTree) edges(c *Concept, end func(*Relation) *RelationEnd) []*Relation {
+	edges := make([]*Relation, 0)
+	for _, rel := range tree.Relations() {
+		e := end(rel)
+		if e.Concept != nil && (&ConceptType{Concept: c}).instanceOf(e.Concept.Concept) != nil {
+			edges = append(edges, rel)
+		}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].QualifiedName() < edges[j].QualifiedName()
+	})
+	return edges
+}
+
+// Describe returns the relation in meme syntax, with its doc
+// comment and the fields of its body.
+func (rel *Relation) Describe() string {
+	var buf bytes.Buffer
+
+	c := rel.concept
+	writeDoc(&buf, c.doc, "")
+	fmt.Fprintf(&buf, "%srelation %s from %s to %s", annotationPrefix(c.annotations), c.QualifiedName(), rel.from, rel.to)
+	if rel.inverseOf != nil {
+		fmt.Fprintf(&buf, " inverse of %s", rel.inverseOf)
+	}
+	for _, prop := range []struct {
+		set  bool
+		name string
+	}{{rel.symmetric, "symmetric"}, {rel.transitive, "transitive"}, {rel.acyclic, "acyclic"}} {
+		if prop.set {
+			fmt.Fprintf(&buf, " %s", prop.name)
+		}
+	}
+
+	fields := c.fields[2:]
+	if len(fields) == 0 {
+		buf.WriteString(" {}")
+		return buf.String()
+	}
+
+	buf.WriteString(" {\n")
+	for _, f := range fields {
+		writeDoc(&buf, f.doc, "\t")
+		fmt.Fprintf(&buf, "\t%s\n", describeField(f))
+	}
+	buf.WriteString("}")
+
+	return buf.String()
+}
+
+// registers every relation declaration under its qualified
+// name, as a concept, and returns those concepts. Relations
+// share their names with concepts and enums.
+func (r *resolver) declareRelations(files []*ast.File) []*Concept {
+	declared := make([]*Concept, 0)
+
+	for _, f := range files {
+		for _, d := range f.Relations {
+			name := qualify(f.PackageName(), d.Name.Name)
+			if previous := r.declaration(name); previous != nil {
+				r.errorf(d.Name, diag.CodeRedeclaredConcept, "relation `%s` redeclared", name).
+					WithSecondary(diag.SpanOf(previous), "previous declaration of `%s`", name)
+				continue
+			}
+
+			// the ends are the first fields of the concept
+			fields := append([]*ast.FieldDecl{endField(d.From), endField(d.To)}, d.Fields...)
+			c := &Concept{
+				name:     d.Name.Name,
+				pkg:      f.PackageName(),
+				doc:      d.Doc.Text(),
+				children: make([]*Concept, 0),
+				decl: &ast.ConceptDecl{
+					Doc:         d.Doc,
+					Annotations: d.Annotations,
+					Concept:     d.Relation,
+					Name:        d.Name,
+					Fields:      fields,
+					Rbrace:      d.Rbrace,
+				},
+				file: f.Name,
+			}
+			c.relation = &Relation{
+				concept: c,
+				from:    &RelationEnd{Cardinality: cardinality(d.From), decl: d.From},
+				to:      &RelationEnd{Cardinality: cardinality(d.To), decl: d.To},
+				decl:    d,
+			}
+
+			r.tree.concepts[name] = c
+			declared = append(declared, c)
+		}
+	}
+
+	return declared
+}
+
+// returns the concept relations extend: the builtin concept
+// Relation if it is declared, or else the root concept
+func (r *resolver) relationBase() *Concept {
+	if base, ok := r.tree.concepts[relationConceptName]; ok && base.decl != nil && base.relation == nil {
+		return base
+	}
+	return r.tree.root
+}
+
+// checks that the ends of relation rel are concepts, once
+// the fields of its concept are resolved
+func (r *resolver) resolveRelationEnds(rel *Relation) {
+	for i, end := range []*RelationEnd{rel.from, rel.to} {
+		f := rel.concept.fields[i]
+		switch t := f.typ.(type) {
+		case nil:
+			// already reported
+		case *ConceptType:
+			end.Concept = t
+		default:
+			r.errorf(end.decl.Type, diag.CodeInvalidRelation, "the ends of relation `%s` must be concepts, found %s `%s`",
+				rel, typeKind(t), t)
+			f.typ = nil
+		}
+	}
+}
+
+// resolves the properties of relation rel, once the ends of
+// every relation are resolved, and checks them against the
+// ends
+func (r *resolver) resolveRelationProperties(rel *Relation) {
+	seen := make(map[string]*ast.RelationProperty)
+
+	for _, d := range rel.decl.Properties {
+		name := d.Name.Name
+		if previous, ok := seen[name]; ok {
+			r.errorf(d, diag.CodeInvalidRelationProperty, "duplicate property `%s` of relation `%s`", name, rel).
+				WithSecondary(diag.SpanOf(previous), "previous `%s`", name)
+			continue
+		}
+		seen[name] = d
+
+		switch name {
+		case "inverse":
+			rel.inverseOf = r.resolveInverse(rel, d)
+			rel.inverse = rel.inverseOf
+		case "symmetric":
+			rel.symmetric = true
+			if !sameEnds(rel.from, rel.to, true) {
+				r.errorf(d, diag.CodeInvalidRelationProperty, "relation `%s` cannot be symmetric: it goes from %s to %s",
+					rel, rel.from, rel.to).
+					WithNote("the ends of a symmetric relation are the same, cardinalities included")
+			}
+		case "transitive", "acyclic":
+			rel.transitive = rel.transitive || name == "transitive"
+			rel.acyclic = rel.acyclic || name == "acyclic"
+			if !sameEnds(rel.from, rel.to, false) {
+				r.errorf(d, diag.CodeInvalidRelationProperty, "relation `%s` cannot be %s: it goes from `%s` to `%s`",
+					rel, name, rel.from.Concept, rel.to.Concept).
+					WithNote("a %s relation relates a concept to itself", name)
+			}
+		}
+	}
+
+	if rel.symmetric && rel.acyclic {
+		r.errorf(seen["acyclic"], diag.CodeInvalidRelationProperty, "relation `%s` cannot be both symmetric and acyclic", rel).
+			WithNote("every edge of a symmetric relation forms a cycle with its reverse")
+	}
+}
+
+// resolves the relation named in "inverse of" and checks that
+// its ends are those of rel swapped
+func (r *resolver) resolveInverse(rel *Relation, d *ast.RelationProperty) *Relation {
+	t := r.resolveNamedType(rel.concept, d.Of)
+	if t == nil {
+		// already reported
+		return nil
+	}
+
+	c, ok := t.(*ConceptType)
+	if !ok || c.Concept.relation == nil {
+		r.errorf(d.Of, diag.CodeInvalidRelationProperty, "`%s` is not a relation", t)
+		return nil
+	}
+
+	other := c.Concept.relation
+	if other == rel {
+		r.errorf(d.Of, diag.CodeInvalidRelationProperty, "relation `%s` cannot be its own inverse", rel).
+			WithNote("declare it symmetric instead")
+		return nil
+	}
+
+	if !sameEnds(rel.from, other.to, true) || !sameEnds(rel.to, other.from, true) {
+		r.errorf(d, diag.CodeInvalidRelationProperty, "relation `%s` cannot be the inverse of `%s`: it goes from %s to %s",
+			rel, other, rel.from, rel.to).
+			WithNote("the inverse of `%s` goes from %s to %s", other, other.to, other.from)
+	}
+	return other
+}
+
+// links every relation named in "inverse of" back to the
+// relation that declares it, once all properties are
+// resolved
+func (r *resolver) linkInverses(relations []*Relation) {
+	for _, rel := range relations {
+		other := rel.inverseOf
+		switch {
+		case other == nil || other.inverse == rel:
+		case other.inverse == nil:
+			other.inverse = rel
+		default:
+			r.errorf(propertyNamed(rel.decl, "inverse").Of, diag.CodeInvalidRelationProperty,
+				"relation `%s` already has an inverse, `%s`", other, other.inverse)
+		}
+	}
+}
+
+// reports whether the relation ends a and b are of the same
+// concept, and of the same cardinality if cardinality is
+// set. Ends that could not be resolved are already reported,
+// so they match anything.
+func sameEnds(a *RelationEnd, b *RelationEnd, cardinality bool) bool {
+	if a.Concept == nil || b.Concept == nil {
+		return true
+	}
+	if cardinality && a.Cardinality != b.Cardinality {
+		return false
+	}
+	return IsSubtype(a.Concept, b.Concept) && IsSubtype(b.Concept, a.Concept)
+}
+
+// returns the first property of d with the given name
+func propertyNamed(d *ast.RelationDecl, name string) *ast.RelationProperty {
+	for _, prop := range d.Properties {
+		if prop.Name.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// the field of a relation concept for one of its ends, named
+// after its keyword
+func endField(e *ast.RelationEnd) *ast.FieldDecl {
+	return &ast.FieldDecl{Modifier: e.Keyword.Pos(), Required: true, Name: e.Keyword, Type: e.Type}
+}
+
+func cardinality(e *ast.RelationEnd) Cardinality {
+	if e.Cardinality != nil && e.Cardinality.Name == "one" {
+		return One
+	}
+	return Many
+}
+
+// describes the kind of a type that is not a concept
+func typeKind(t Type) string {
+	switch t.(type) {
+	case *EnumType:
+		return "enum"
+	case *TypeParamType:
+		return "type parameter"
+	default:
+		return "type"
+	}
+}
@@ -0,0 +1,212 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/diag"
+)
+
+var _ = Describe("Relations", func() {
+	Context("Resolving valid relations", func() {
+		It("Should resolve the ends and the fields of relations", func() {
+			tree, err := resolve(parseSources(
+				`concept Relation {
+					required from oneof(Concept, Relation)
+					required to oneof(Concept, Relation)
+				}`,
+				`import "a.meme"
+				concept User { required name string }
+				concept Issue {}
+				concept Bug extends Issue {}
+
+				@doc("who works on an issue")
+				relation AssignedTo from many Issue to one User {
+					optional since string
+				}
+				relation RelatedTo from Issue to Issue {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			rel := tree.LookupRelation("AssignedTo")
+			Expect(rel).NotTo(BeNil())
+			Expect(rel.From().Concept.Concept).To(Equal(tree.Lookup("Issue")))
+			Expect(rel.From().Cardinality).To(Equal(Many))
+			Expect(rel.To().Concept.Concept).To(Equal(tree.Lookup("User")))
+			Expect(rel.To().Cardinality).To(Equal(One))
+			Expect(rel.File()).To(Equal("b.meme"))
+			Expect(tree.LookupRelation("User")).To(BeNil())
+
+			c := tree.Lookup("AssignedTo")
+			Expect(c.Relation()).To(Equal(rel))
+			Expect(c.Parent()).To(Equal(tree.Lookup("Relation")))
+			Expect(fieldStrings(c.AllFields())).To(Equal([]string{
+				"required from Issue",
+				"required to User",
+				"optional since string",
+			}))
+
+			Expect(rel.Describe()).To(Equal(strings.Join([]string{
+				`@doc("who works on an issue") relation AssignedTo from many Issue to one User {`,
+				"\toptional since string",
+				"}",
+			}, "\n")))
+			Expect(tree.LookupRelation("RelatedTo").Describe()).To(Equal("relation RelatedTo from many Issue to many Issue {}"))
+		})
+
+		It("Should query relations as edges of concepts and their descendants", func() {
+			tree, err := resolve(parseSources(
+				`concept User {}
+				concept Issue {}
+				concept Bug extends Issue {}
+				relation Reported from many Issue to one User {}
+				relation Blocks from Issue to Issue {}
+				relation Fixes from Bug to Issue {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			names := func(list []*Relation) []string {
+				s := make([]string, len(list))
+				for i, rel := range list {
+					s[i] = rel.String()
+				}
+				return s
+			}
+
+			Expect(names(tree.Relations())).To(Equal([]string{"Blocks", "Fixes", "Reported"}))
+			Expect(names(tree.RelationsFrom(tree.Lookup("Bug")))).To(Equal([]string{"Blocks", "Fixes", "Reported"}))
+			Expect(names(tree.RelationsFrom(tree.Lookup("Issue")))).To(Equal([]string{"Blocks", "Reported"}))
+			Expect(names(tree.RelationsTo(tree.Lookup("Issue")))).To(Equal([]string{"Blocks", "Fixes"}))
+			Expect(names(tree.RelationsTo(tree.Lookup("User")))).To(Equal([]string{"Reported"}))
+
+			// without the builtin Relation, relations extend the root
+			Expect(tree.Lookup("Blocks").Parent()).To(Equal(tree.Root()))
+		})
+
+		It("Should name relations of a package with their package", func() {
+			tree, err := resolve(parseSources(
+				`package scrum
+				concept Issue {}
+				relation Blocks from Issue to Issue {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.LookupRelation("scrum.Blocks").Describe()).To(Equal("relation scrum.Blocks from many scrum.Issue to many scrum.Issue {}"))
+		})
+	})
+
+	Context("Resolving relation properties", func() {
+		It("Should record the properties and link inverses both ways", func() {
+			tree, err := resolve(parseSources(
+				`concept Issue {}
+				relation Blocks from many Issue to one Issue acyclic {}
+				relation BlockedBy from one Issue to many Issue inverse of Blocks {}
+				relation RelatedTo from Issue to Issue symmetric {}
+				relation PartOf from Issue to Issue transitive acyclic {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			blocks, blockedBy := tree.LookupRelation("Blocks"), tree.LookupRelation("BlockedBy")
+			Expect(blocks.Acyclic()).To(BeTrue())
+			Expect(blocks.Transitive()).To(BeFalse())
+			Expect(blocks.Inverse()).To(Equal(blockedBy))
+			Expect(blockedBy.Inverse()).To(Equal(blocks))
+			Expect(tree.LookupRelation("RelatedTo").Symmetric()).To(BeTrue())
+			Expect(tree.LookupRelation("RelatedTo").Inverse()).To(BeNil())
+
+			partOf := tree.LookupRelation("PartOf")
+			Expect(partOf.Transitive()).To(BeTrue())
+			Expect(partOf.Acyclic()).To(BeTrue())
+			Expect(partOf.Describe()).To(Equal("relation PartOf from many Issue to many Issue transitive acyclic {}"))
+			Expect(blockedBy.Describe()).To(Equal("relation BlockedBy from one Issue to many Issue inverse of Blocks {}"))
+		})
+
+		It("Should report properties that do not fit the ends", func() {
+			_, err := resolve(parseSources(
+				`concept Issue {}
+				concept Bug extends Issue {}
+				relation A from many Issue to one Issue symmetric {}
+				relation B from Issue to Bug transitive {}
+				relation C from Issue to Issue acyclic symmetric {}
+				relation D from Issue to Bug inverse of B {}
+				relation E from Issue to Issue inverse of E {}
+				relation F from Issue to Issue inverse of Issue {}
+				relation G from Issue to Issue acyclic acyclic {}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:3:45: relation `A` cannot be symmetric: it goes from many Issue to one Issue",
+				"a.meme:4:34: relation `B` cannot be transitive: it goes from `Issue` to `Bug`",
+				"a.meme:5:36: relation `C` cannot be both symmetric and acyclic",
+				"a.meme:6:34: relation `D` cannot be the inverse of `B`: it goes from many Issue to many Bug",
+				"a.meme:7:47: relation `E` cannot be its own inverse",
+				"a.meme:8:47: `Issue` is not a relation",
+				"a.meme:9:44: duplicate property `acyclic` of relation `G`",
+			}, "\n")))
+
+			list := err.(diag.List)
+			Expect(list[0].Code).To(Equal(diag.CodeInvalidRelationProperty))
+			Expect(list[3].Notes).To(ConsistOf("the inverse of `B` goes from many Bug to many Issue"))
+		})
+
+		It("Should report relations declared the inverse of the same relation", func() {
+			_, err := resolve(parseSources(
+				`concept Issue {}
+				relation Blocks from Issue to Issue {}
+				relation BlockedBy from Issue to Issue inverse of Blocks {}
+				relation WaitsFor from Issue to Issue inverse of Blocks {}`,
+			))
+			Expect(err).To(MatchError("a.meme:4:54: relation `Blocks` already has an inverse, `BlockedBy`"))
+		})
+	})
+
+	Context("Resolving invalid relations", func() {
+		It("Should report ends that are not concepts", func() {
+			_, err := resolve(parseSources(
+				`enum Status { Open }
+				concept Issue {}
+				relation A from Status to Issue {}
+				relation B from Issue to Missing {}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:3:21: the ends of relation `A` must be concepts, found enum `Status`",
+				"a.meme:4:30: undefined name `Missing`: not a concept, an enum or a type parameter of `B`",
+			}, "\n")))
+			Expect(err.(diag.List)[0].Code).To(Equal(diag.CodeInvalidRelation))
+		})
+
+		It("Should report fields that redeclare the ends of a relation", func() {
+			_, err := resolve(parseSources(
+				`concept Issue {}
+				relation Blocks from Issue to Issue {
+					required to Issue
+					optional since string
+					optional since string
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:3:15: field `to` redeclared in relation `Blocks`, whose ends are the implicit fields `from` and `to`",
+				"a.meme:5:15: field `since` redeclared in relation `Blocks`",
+			}, "\n")))
+
+			list := err.(diag.List)
+			Expect(list[0].Code).To(Equal(diag.CodeRedeclaredField))
+			Expect(list[0].Secondary).To(HaveLen(1))
+			Expect(list[0].Secondary[0].Message).To(Equal("the `to` end of `Blocks`"))
+		})
+
+		It("Should report relations that take the name of another declaration", func() {
+			_, err := resolve(parseSources(
+				`concept Issue {}
+				relation Issue from Issue to Issue {}
+				relation Blocks from Issue to Issue { required from string }
+				@min(1) relation Fixes from Issue to Issue {}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:2:14: relation `Issue` redeclared",
+				"a.meme:3:52: field `from` redeclared in relation `Blocks`, whose ends are the implicit fields `from` and `to`",
+				"a.meme:4:5: @min cannot be used on relation `Fixes`",
+			}, "\n")))
+		})
+	})
+})
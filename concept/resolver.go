@@ -0,0 +1,357 @@
+package concept
+
+import (
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// Resolve registers every concept, enum and relation
+// declared in files under its name, links each concept to
+// the concept it extends and resolves the types of all
+// fields, the members of all enums and the ends of all
+// relations. Concepts without an extends clause become
+// children of the root concept.
+// Inheritance cycles are reported and broken, and every
+// concept inherits the fields of its ancestors; see
+// Concept.AllFields.
+//
+// Names are only resolved to declarations of the same file,
+// of the files it imports and to the root concept. Imports
+// are matched against the names of the files, which are
+// paths; use package loader to read files along with the
+// files they import.
+//
+// The files must have been parsed with fset. All errors
+// found are returned as a diag.List.
+func (tree *ConceptTree) Resolve(fset *token.FileSet, files ...*ast.File) error {
+	r := &resolver{tree: tree, fset: fset}
+
+	r.declareFiles(files)
+	declared := r.declare(files)
+	enums := r.declareEnums(files)
+	declared = append(declared, r.declareRelations(files)...)
+	for _, e := range enums {
+		r.resolveEnum(e)
+	}
+	for _, c := range declared {
+		r.resolveTypeParams(c)
+	}
+	for _, c := range declared {
+		r.resolveExtends(c)
+	}
+	r.checkCycles(declared)
+	for _, c := range declared {
+		r.resolveConceptAnnotations(c)
+		r.resolveFields(c)
+		if c.relation != nil {
+			r.resolveRelationEnds(c.relation)
+		}
+	}
+
+	relations := make([]*Relation, 0)
+	for _, c := range declared {
+		if c.relation != nil {
+			relations = append(relations, c.relation)
+		}
+	}
+	for _, rel := range relations {
+		r.resolveRelationProperties(rel)
+	}
+	r.linkInverses(relations)
+
+	// concepts resolved by an earlier call are already done
+	done := make(map[*Concept]bool)
+	for _, c := range tree.concepts {
+		done[c] = c.decl != nil
+	}
+	for _, c := range declared {
+		done[c] = false
+	}
+	for _, c := range declared {
+		r.inheritFields(c, done)
+	}
+
+	r.diagnostics.Sort()
+	return r.diagnostics.Err()
+}
+
+type resolver struct {
+	tree        *ConceptTree
+	fset        *token.FileSet
+	diagnostics diag.List
+}
+
+// records an error at node n and returns it, so that
+// secondary labels and notes can be added
+func (r *resolver) errorf(n diag.Node, code diag.Code, format string, args ...interface{}) *diag.Diagnostic {
+	d := diag.Errorf(r.fset, diag.SpanOf(n), code, format, args...)
+	r.diagnostics.Add(d)
+	return d
+}
+
+// registers every declaration under its qualified name and
+// returns the concepts in declaration order
+func (r *resolver) declare(files []*ast.File) []*Concept {
+	declared := make([]*Concept, 0)
+
+	for _, f := range files {
+		for _, d := range f.Concepts {
+			name := qualify(f.PackageName(), d.Name.Name)
+			c, ok := r.tree.concepts[name]
+
+			if e, isEnum := r.tree.enums[name]; isEnum {
+				r.errorf(d.Name, diag.CodeRedeclaredConcept, "concept `%s` redeclared", name).
+					WithSecondary(diag.SpanOf(e.decl.Name), "previous declaration of enum `%s`", name)
+				continue
+			}
+
+			switch {
+			case ok && c.decl != nil:
+				r.errorf(d.Name, diag.CodeRedeclaredConcept, "concept `%s` redeclared", name).
+					WithSecondary(diag.SpanOf(c.decl.Name), "previous declaration of `%s`", name)
+				continue
+			case ok:
+				// the root concept is predefined, this is its
+				// declaration in builtin/special/concept.meme
+			default:
+				c = &Concept{name: d.Name.Name, pkg: f.PackageName(), children: make([]*Concept, 0)}
+				r.tree.concepts[name] = c
+			}
+
+			c.decl = d
+			c.doc = d.Doc.Text()
+			c.file = f.Name
+			declared = append(declared, c)
+		}
+	}
+
+	return declared
+}
+
+// resolves the type parameters of the concept. This is done
+// for every concept before any type is resolved, so that the
+// number of type arguments can be checked at each reference.
+func (r *resolver) resolveTypeParams(c *Concept) {
+	for _, param := range c.decl.TypeParams {
+		if c.typeParam(param.Name) != nil {
+			r.errorf(param, diag.CodeRedeclaredTypeParam, "type parameter `%s` redeclared in concept `%s`", param.Name, c)
+			continue
+		}
+		c.typeParams = append(c.typeParams, &TypeParam{Owner: c, Name: param.Name, Index: len(c.typeParams)})
+	}
+}
+
+// resolves the extends clause and links c to its parent
+func (r *resolver) resolveExtends(c *Concept) {
+	d := c.decl
+
+	if c == r.tree.root {
+		if d.Extends != nil {
+			r.errorf(d.Extends, diag.CodeInvalidExtends, "the root concept `%s` cannot extend another concept", c)
+		}
+		return
+	}
+
+	parent := &ConceptType{Concept: r.tree.root}
+	if c.relation != nil {
+		parent = &ConceptType{Concept: r.relationBase()}
+	}
+	if d.Extends != nil {
+		switch t := r.resolveNamedType(c, d.Extends).(type) {
+		case *ConceptType:
+			parent = t
+		case *EnumType:
+			r.errorf(d.Extends, diag.CodeInvalidExtends, "concept `%s` cannot extend enum `%s`", c, t)
+		case *TypeParamType:
+			r.errorf(d.Extends, diag.CodeInvalidExtends, "concept `%s` cannot extend type parameter `%s`", c, t)
+		}
+	}
+
+	c.extends = parent
+	c.parent = parent.Concept
+	parent.Concept.children = append(parent.Concept.children, c)
+}
+
+// reports field d, declared after field previous of the
+// same name in the body of concept c
+func (r *resolver) redeclaredField(c *Concept, previous *Field, d *ast.FieldDecl) {
+	if c.relation == nil {
+		r.errorf(d.Name, diag.CodeRedeclaredField, "field `%s` redeclared in concept `%s`", d.Name.Name, c)
+		return
+	}
+	if previous.decl.Name == c.relation.from.decl.Keyword || previous.decl.Name == c.relation.to.decl.Keyword {
+		r.errorf(d.Name, diag.CodeRedeclaredField, "field `%s` redeclared in relation `%s`, whose ends are the implicit fields `from` and `to`", d.Name.Name, c).
+			WithSecondary(diag.SpanOf(previous.decl.Name), "the `%s` end of `%s`", d.Name.Name, c)
+		return
+	}
+	r.errorf(d.Name, diag.CodeRedeclaredField, "field `%s` redeclared in relation `%s`", d.Name.Name, c)
+}
+
+// resolves the fields declared in the body of the concept
+func (r *resolver) resolveFields(c *Concept) {
+	for _, d := range c.decl.Fields {
+		if previous := c.field(d.Name.Name); previous != nil {
+			r.redeclaredField(c, previous, d)
+			continue
+		}
+
+		f := &Field{
+			owner:    c,
+			name:     d.Name.Name,
+			required: d.Required,
+			typ:      r.resolveType(c, d.Type),
+			doc:      d.Doc.Text(),
+			decl:     d,
+		}
+		r.resolveFieldAnnotations(f)
+		if d.Default != nil {
+			r.resolveDefault(f)
+		}
+		c.fields = append(c.fields, f)
+	}
+}
+
+// resolves the default value of field f and checks that it
+// is a value of the type of the field
+func (r *resolver) resolveDefault(f *Field) {
+	if f.required {
+		r.errorf(f.decl.Default, diag.CodeRequiredDefault, "required field `%s` cannot have a default value", f.name).
+			WithNote("make the field optional, it takes the default value when it is missing")
+		return
+	}
+
+	if f.typ == nil {
+		// already reported
+		return
+	}
+
+	v, m := valueOf(f.decl.Default, f.typ)
+	if m != nil {
+		r.errorf(m.expr, diag.CodeInvalidDefault, "invalid default value for field `%s`: %s", f.name, m.message)
+		return
+	}
+	if !r.checkDefault(f, v) {
+		return
+	}
+	f.defaultValue = v
+}
+
+// resolves a type expression used inside concept c. Returns
+// nil if the type (or part of it) could not be resolved.
+func (r *resolver) resolveType(c *Concept, expr ast.TypeExpr) Type {
+	switch e := expr.(type) {
+	case *ast.PrimitiveType:
+		switch e.Kind {
+		case token.TokenIntegerType:
+			return &PrimitiveType{Integer}
+		case token.TokenStringType:
+			return &PrimitiveType{String}
+		case token.TokenBooleanType:
+			return &PrimitiveType{Boolean}
+		}
+
+	case *ast.NamedType:
+		return r.resolveNamedType(c, e)
+
+	case *ast.ListType:
+		if elem := r.resolveType(c, e.Elem); elem != nil {
+			return &ListType{elem}
+		}
+
+	case *ast.TupleType:
+		if elems, ok := r.resolveTypeList(c, e.Elems); ok {
+			return &TupleType{elems}
+		}
+
+	case *ast.OneOfType:
+		if options, ok := r.resolveTypeList(c, e.Options); ok {
+			return &OneOfType{options}
+		}
+
+	case *ast.AnyOfType:
+		if options, ok := r.resolveTypeList(c, e.Options); ok {
+			return &AnyOfType{options}
+		}
+	}
+
+	return nil
+}
+
+// resolves a name to a type parameter of c, an enum or a
+// concept
+func (r *resolver) resolveNamedType(c *Concept, e *ast.NamedType) Type {
+	args, ok := r.resolveTypeList(c, e.Args)
+
+	if param := c.typeParam(e.Name.Name); param != nil && e.Package == nil {
+		if len(e.Args) > 0 {
+			r.errorf(e.Name, diag.CodeTypeParamWithArguments, "type parameter `%s` cannot have type arguments", param.Name)
+			return nil
+		}
+		return &TypeParamType{param}
+	}
+
+	name, found := r.lookup(c, e)
+	if !found {
+		return nil
+	}
+
+	if enum, found := r.tree.enums[name]; found {
+		if !r.checkVisible(c, e, enum.file) {
+			return nil
+		}
+		if len(e.Args) > 0 {
+			r.errorf(e.Name, diag.CodeTypeArgumentCount, "wrong number of type arguments for `%s`: expected 0, found %d", name, len(e.Args))
+			return nil
+		}
+		return &EnumType{enum}
+	}
+
+	target := r.tree.concepts[name]
+	if target != r.tree.root && !r.checkVisible(c, e, target.file) {
+		return nil
+	}
+
+	if len(e.Args) != len(target.typeParams) {
+		r.errorf(e.Name, diag.CodeTypeArgumentCount, "wrong number of type arguments for `%s`: expected %d, found %d",
+			name, len(target.typeParams), len(e.Args))
+		return nil
+	}
+
+	if !ok {
+		return nil
+	}
+	return &ConceptType{Concept: target, Args: args}
+}
+
+func (r *resolver) resolveTypeList(c *Concept, list []ast.TypeExpr) ([]Type, bool) {
+	types := make([]Type, 0, len(list))
+	ok := true
+	for _, expr := range list {
+		if t := r.resolveType(c, expr); t != nil {
+			types = append(types, t)
+		} else {
+			ok = false
+		}
+	}
+
+	return types, ok
+}
+
+func (c *Concept) typeParam(name string) *TypeParam {
+	for _, param := range c.typeParams {
+		if param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
+func (c *Concept) field(name string) *Field {
+	for _, f := range c.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package concept
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/parser"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// the set every file parsed by the tests is added to
+var fset = token.NewFileSet()
+
+// parses each source, naming the files a.meme, b.meme, ...
+func parseSources(sources ...string) []*ast.File {
+	files := make([]*ast.File, len(sources))
+	for i, src := range sources {
+		f, err := parser.ParseSource(fset, string(rune('a'+i))+".meme", src)
+		Expect(err).NotTo(HaveOccurred())
+		files[i] = f
+	}
+	return files
+}
+
+// parses every .meme file under the given directories
+func parseDirs(dirs ...string) []*ast.File {
+	files := make([]*ast.File, 0)
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !strings.HasSuffix(path, ".meme") {
+				return err
+			}
+
+			data, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			f, err := parser.ParseSource(fset, path, string(data))
+			Expect(err).NotTo(HaveOccurred(), path)
+			files = append(files, f)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+	return files
+}
+
+// resolves files with the builtin library of the repository
+func resolve(files []*ast.File) (*ConceptTree, error) {
+	tree := NewConceptTree()
+	tree.SetBuiltin("../builtin")
+	return tree, tree.Resolve(fset, files...)
+}
+
+var _ = Describe("Resolve", func() {
+	Context("Resolving valid files", func() {
+		It("Should register concepts and link parents to children", func() {
+			tree, err := resolve(parseSources(
+				`concept Animal { required name string }`,
+				`import "a.meme"
+				 concept Dog extends Animal { optional owner Person }
+				 concept Person { required pets [Animal] }`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			animal := tree.Lookup("Animal")
+			dog := tree.Lookup("Dog")
+			person := tree.Lookup("Person")
+			Expect(animal).NotTo(BeNil())
+			Expect(dog.Parent()).To(Equal(animal))
+			Expect(animal.Children()).To(ConsistOf(dog))
+			Expect(animal.Parent()).To(Equal(tree.Root()))
+			Expect(tree.Root().Children()).To(ConsistOf(animal, person))
+			Expect(dog.File()).To(Equal("b.meme"))
+		})
+
+		It("Should resolve field types", func() {
+			tree, err := resolve(parseSources(
+				`concept Pair<K, V> {
+					required first K
+					required rest [(V, oneof(integer, Pair<K, string>))]
+				}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			fields := tree.Lookup("Pair").Fields()
+			Expect(fields).To(HaveLen(2))
+			Expect(fields[0].Required()).To(BeTrue())
+			Expect(fields[0].Type()).To(BeAssignableToTypeOf(&TypeParamType{}))
+			Expect(fields[1].Type().String()).To(Equal("[(V, oneof(integer, Pair<K, string>))]"))
+		})
+
+		It("Should attach the declaration of the root concept", func() {
+			tree, err := resolve(parseSources(`concept Concept {}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.Root().Decl()).NotTo(BeNil())
+			Expect(tree.Root().Parent()).To(BeNil())
+		})
+	})
+
+	Context("Resolving invalid files", func() {
+		It("Should report unknown names with file and line", func() {
+			_, err := resolve(parseSources(`concept A {
+				required b B
+			}`))
+			Expect(err).To(MatchError("a.meme:2:16: undefined name `B`: not a concept, an enum or a type parameter of `A`"))
+		})
+
+		It("Should report duplicate concepts", func() {
+			_, err := resolve(parseSources(`concept A {}`, `
+				concept A {}`))
+			Expect(err).To(MatchError("b.meme:2:13: concept `A` redeclared"))
+
+			d := err.(diag.List)[0]
+			Expect(d.Code).To(Equal(diag.CodeRedeclaredConcept))
+			Expect(d.Secondary).To(HaveLen(1))
+			Expect(fset.Position(d.Secondary[0].Span.Pos).String()).To(Equal("a.meme:1:9"))
+		})
+
+		It("Should report duplicate fields and type parameters", func() {
+			_, err := resolve(parseSources(`concept A<T, T> {
+				required b string
+				optional b integer
+			}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.(diag.List)).To(HaveLen(2))
+			Expect(err.Error()).To(ContainSubstring("type parameter `T` redeclared"))
+			Expect(err.Error()).To(ContainSubstring("field `b` redeclared"))
+		})
+
+		It("Should report every error, sorted by file and line", func() {
+			_, err := resolve(parseSources(
+				`concept B { required x Y }`,
+				`concept A extends Z {
+					required x X
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:24: undefined name `Y`: not a concept, an enum or a type parameter of `B`",
+				"b.meme:1:19: undefined name `Z`: not a concept, an enum or a type parameter of `A`",
+				"b.meme:2:17: undefined name `X`: not a concept, an enum or a type parameter of `A`",
+			}, "\n")))
+		})
+	})
+
+	Context("Resolving the bundled schemas", func() {
+		It("Should resolve the builtin library", func() {
+			tree, err := resolve(parseDirs("../builtin"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.Lookup("TypedList").Parent()).To(Equal(tree.Lookup("List")))
+		})
+
+		It("Should resolve the todolist example", func() {
+			_, err := resolve(parseDirs("../builtin", "../examples/todolist"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should resolve the scrum board example", func() {
+			_, err := resolve(parseDirs("../builtin", "../examples/scrum_board"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
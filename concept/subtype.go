@@ -0,0 +1,193 @@
+package concept
+
+// IsSubtype reports whether every value of type sub is also
+// a value of type super. The rules are:
+//
+//   - every type is a subtype of the root concept, Concept
+//   - a primitive type is a subtype of itself only
+//   - a concept type is a subtype of its ancestors, provided
+//     each type argument is a subtype of the corresponding
+//     argument of the ancestor
+//   - a type parameter and an enum are subtypes of
+//     themselves only
+//   - [A] is a subtype of [B] if A is a subtype of B, and
+//     tuples are compared element by element
+//   - oneof(A, ...) and anyof(A, ...) are subtypes of T if
+//     each of their options is a subtype of T, and T is a
+//     subtype of oneof(B, ...) and anyof(B, ...) if T is a
+//     subtype of one of the options
+func IsSubtype(sub, super Type) bool {
+	if isRoot(super) {
+		return true
+	}
+
+	// unions on the left have to be handled first, e.g.
+	// oneof(A, B) is a subtype of oneof(A, B, C) because
+	// both A and B are
+	switch s := sub.(type) {
+	case *OneOfType:
+		return allSubtypes(s.Options, super)
+	case *AnyOfType:
+		return allSubtypes(s.Options, super)
+	}
+
+	switch t := super.(type) {
+	case *OneOfType:
+		return anySupertype(sub, t.Options)
+	case *AnyOfType:
+		return anySupertype(sub, t.Options)
+
+	case *PrimitiveType:
+		s, ok := sub.(*PrimitiveType)
+		return ok && s.Kind == t.Kind
+
+	case *TypeParamType:
+		s, ok := sub.(*TypeParamType)
+		return ok && s.Param == t.Param
+
+	case *EnumType:
+		s, ok := sub.(*EnumType)
+		return ok && s.Enum == t.Enum
+
+	case *ListType:
+		s, ok := sub.(*ListType)
+		return ok && IsSubtype(s.Elem, t.Elem)
+
+	case *TupleType:
+		s, ok := sub.(*TupleType)
+		if !ok || len(s.Elems) != len(t.Elems) {
+			return false
+		}
+		for i := range s.Elems {
+			if !IsSubtype(s.Elems[i], t.Elems[i]) {
+				return false
+			}
+		}
+		return true
+
+	case *ConceptType:
+		s, ok := sub.(*ConceptType)
+		if !ok {
+			return false
+		}
+		ancestor := s.instanceOf(t.Concept)
+		if ancestor == nil || len(ancestor.Args) != len(t.Args) {
+			return false
+		}
+		for i := range t.Args {
+			if !IsSubtype(ancestor.Args[i], t.Args[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func isRoot(t Type) bool {
+	c, ok := t.(*ConceptType)
+	return ok && c.Concept.parent == nil && c.Concept.name == rootConceptName
+}
+
+func allSubtypes(options []Type, super Type) bool {
+	for _, option := range options {
+		if !IsSubtype(option, super) {
+			return false
+		}
+	}
+	return true
+}
+
+func anySupertype(sub Type, options []Type) bool {
+	for _, option := range options {
+		if IsSubtype(sub, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsA reports whether c is other or descends from it.
+func (c *Concept) IsA(other *Concept) bool {
+	for ; c != nil; c = c.parent {
+		if c == other {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceOf walks up the ancestors of t and returns the
+// ancestor whose concept is target, instantiated with the
+// type arguments implied by t. For example, if
+//
+//	concept TodoList extends TypedList<TodoListItem>
+//
+// then TodoList.instanceOf(TypedList) is TypedList<TodoListItem>.
+// Returns nil if target is not an ancestor of t.
+func (t *ConceptType) instanceOf(target *Concept) *ConceptType {
+	for t != nil {
+		if t.Concept == target {
+			return t
+		}
+		if t.Concept.extends == nil {
+			return nil
+		}
+		t = substitute(t.Concept.extends, t.bindings()).(*ConceptType)
+	}
+	return nil
+}
+
+// bindings maps each type parameter of the concept to the
+// corresponding type argument of t. Parameters without a
+// matching argument are left unbound.
+func (t *ConceptType) bindings() map[*TypeParam]Type {
+	bindings := make(map[*TypeParam]Type, len(t.Args))
+	for i, param := range t.Concept.typeParams {
+		if i < len(t.Args) {
+			bindings[param] = t.Args[i]
+		}
+	}
+	return bindings
+}
+
+// substitute replaces every bound type parameter in t with
+// the type it is bound to.
+func substitute(t Type, bindings map[*TypeParam]Type) Type {
+	if len(bindings) == 0 || t == nil {
+		return t
+	}
+
+	switch t := t.(type) {
+	case *TypeParamType:
+		if bound, ok := bindings[t.Param]; ok {
+			return bound
+		}
+		return t
+	case *ConceptType:
+		return &ConceptType{Concept: t.Concept, Args: substituteList(t.Args, bindings)}
+	case *ListType:
+		return &ListType{substitute(t.Elem, bindings)}
+	case *TupleType:
+		return &TupleType{substituteList(t.Elems, bindings)}
+	case *OneOfType:
+		return &OneOfType{substituteList(t.Options, bindings)}
+	case *AnyOfType:
+		return &AnyOfType{substituteList(t.Options, bindings)}
+	default:
+		return t
+	}
+}
+
+func substituteList(list []Type, bindings map[*TypeParam]Type) []Type {
+	if list == nil {
+		return nil
+	}
+
+	substituted := make([]Type, len(list))
+	for i, t := range list {
+		substituted[i] = substitute(t, bindings)
+	}
+	return substituted
+}
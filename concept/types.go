@@ -0,0 +1,119 @@
+package concept
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is a resolved type expression.
+type Type interface {
+	String() string
+	typeNode()
+}
+
+type PrimitiveKind int
+
+const (
+	Integer PrimitiveKind = iota
+	String
+	Boolean
+)
+
+var primitiveKindString = map[PrimitiveKind]string{
+	Integer: "integer",
+	String:  "string",
+	Boolean: "boolean",
+}
+
+func (kind PrimitiveKind) String() string {
+	return primitiveKindString[kind]
+}
+
+// PrimitiveType is one of integer, string or boolean.
+type PrimitiveType struct {
+	Kind PrimitiveKind
+}
+
+// ConceptType is a reference to a concept, instantiated
+// with Args if the concept is generic.
+type ConceptType struct {
+	Concept *Concept
+	Args    []Type
+}
+
+// TypeParamType is a reference to a type parameter of
+// the concept it is used in.
+type TypeParamType struct {
+	Param *TypeParam
+}
+
+// TypeParam is a type parameter declared by a generic
+// concept, such as T in TypedList<T>.
+type TypeParam struct {
+	Owner *Concept
+	Name  string
+	Index int
+}
+
+type ListType struct {
+	Elem Type
+}
+
+type TupleType struct {
+	Elems []Type
+}
+
+type OneOfType struct {
+	Options []Type
+}
+
+type AnyOfType struct {
+	Options []Type
+}
+
+func (t *PrimitiveType) typeNode() {}
+func (t *ConceptType) typeNode()   {}
+func (t *TypeParamType) typeNode() {}
+func (t *ListType) typeNode()      {}
+func (t *TupleType) typeNode()     {}
+func (t *OneOfType) typeNode()     {}
+func (t *AnyOfType) typeNode()     {}
+
+func (t *PrimitiveType) String() string {
+	return t.Kind.String()
+}
+
+func (t *ConceptType) String() string {
+	if len(t.Args) == 0 {
+		return t.Concept.QualifiedName()
+	}
+	return fmt.Sprintf("%s<%s>", t.Concept.QualifiedName(), typeListString(t.Args))
+}
+
+func (t *TypeParamType) String() string {
+	return t.Param.Name
+}
+
+func (t *ListType) String() string {
+	return fmt.Sprintf("[%s]", t.Elem)
+}
+
+func (t *TupleType) String() string {
+	return fmt.Sprintf("(%s)", typeListString(t.Elems))
+}
+
+func (t *OneOfType) String() string {
+	return fmt.Sprintf("oneof(%s)", typeListString(t.Options))
+}
+
+func (t *AnyOfType) String() string {
+	return fmt.Sprintf("anyof(%s)", typeListString(t.Options))
+}
+
+func typeListString(list []Type) string {
+	s := make([]string, len(list))
+	for i, t := range list {
+		s[i] = t.String()
+	}
+	return strings.Join(s, ", ")
+}
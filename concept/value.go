@@ -0,0 +1,268 @@
+package concept
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// Value is a resolved literal, e.g. the default value of a
+// field. It is an int64, a float64, a string, a bool, a
+// ListValue, a TupleValue, an *EnumMember for the values of
+// enum types, or a Name.
+type Value interface{}
+
+type ListValue []Value
+
+type TupleValue []Value
+
+// Name is an identifier written as a value, as in the
+// arguments of annotations that are not built in, which the
+// resolver does not give a meaning to.
+type Name string
+
+// FormatValue returns v in meme syntax.
+func FormatValue(v Value) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eIN") {
+			// keep it a float literal
+			s += ".0"
+		}
+		return s
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case ListValue:
+		return "[" + formatValueList(v) + "]"
+	case TupleValue:
+		return "(" + formatValueList(v) + ")"
+	case *EnumMember:
+		return v.name
+	case Name:
+		return string(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func formatValueList(list []Value) string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = FormatValue(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+// a value, or a part of it, that does not match its type
+type mismatch struct {
+	expr    ast.Expr
+	message string
+}
+
+func mismatchf(e ast.Expr, format string, args ...interface{}) *mismatch {
+	return &mismatch{e, fmt.Sprintf(format, args...)}
+}
+
+// returns the value of e as a value of type t
+func valueOf(e ast.Expr, t Type) (Value, *mismatch) {
+	switch t := t.(type) {
+	case *PrimitiveType:
+		if lit, ok := e.(*ast.BasicLit); ok {
+			if kind, ok := literalKind[lit.Kind]; ok && kind == t.Kind {
+				return basicValue(lit)
+			}
+		}
+
+	case *ListType:
+		list, ok := e.(*ast.ListLit)
+		if !ok {
+			break
+		}
+
+		values := make(ListValue, len(list.Elems))
+		for i, elem := range list.Elems {
+			v, m := valueOf(elem, t.Elem)
+			if m != nil {
+				return nil, m
+			}
+			values[i] = v
+		}
+		return values, nil
+
+	case *TupleType:
+		tuple, ok := e.(*ast.TupleLit)
+		if !ok || len(tuple.Elems) != len(t.Elems) {
+			break
+		}
+
+		values := make(TupleValue, len(tuple.Elems))
+		for i, elem := range tuple.Elems {
+			v, m := valueOf(elem, t.Elems[i])
+			if m != nil {
+				return nil, m
+			}
+			values[i] = v
+		}
+		return values, nil
+
+	case *OneOfType:
+		return valueOfOption(e, t, t.Options)
+
+	case *AnyOfType:
+		return valueOfOption(e, t, t.Options)
+
+	case *ConceptType:
+		if isRoot(t) {
+			// every value is a Concept
+			return untypedValue(e, false)
+		}
+		return nil, mismatchf(e, "a value of concept type `%s` cannot be written as a literal", t)
+
+	case *TypeParamType:
+		return nil, mismatchf(e, "a value of type parameter type `%s` cannot be written as a literal", t)
+
+	case *EnumType:
+		if id, ok := e.(*ast.Ident); ok {
+			if m := t.Enum.Member(id.Name); m != nil {
+				return m, nil
+			}
+			return nil, mismatchf(e, "`%s` is not a member of enum `%s`", id.Name, t)
+		}
+	}
+
+	return nil, mismatchf(e, "cannot use %s as a value of type `%s`", exprString(e), t)
+}
+
+// the primitive types of literals. Float literals are only
+// values of Concept.
+var literalKind = map[token.TokenType]PrimitiveKind{
+	token.TokenIntegerLiteral: Integer,
+	token.TokenStringLiteral:  String,
+	token.TokenTrue:           Boolean,
+	token.TokenFalse:          Boolean,
+}
+
+// returns the value of e as the first of the options it is
+// a value of
+func valueOfOption(e ast.Expr, t Type, options []Type) (Value, *mismatch) {
+	for _, option := range options {
+		if v, m := valueOf(e, option); m == nil {
+			return v, nil
+		}
+	}
+	return nil, mismatchf(e, "cannot use %s as a value of type `%s`", exprString(e), t)
+}
+
+// returns the value of e with the type of its literals.
+// Identifiers are Names if names is set, errors otherwise.
+func untypedValue(e ast.Expr, names bool) (Value, *mismatch) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return basicValue(e)
+
+	case *ast.ListLit:
+		values := make(ListValue, len(e.Elems))
+		for i, elem := range e.Elems {
+			v, m := untypedValue(elem, names)
+			if m != nil {
+				return nil, m
+			}
+			values[i] = v
+		}
+		return values, nil
+
+	case *ast.TupleLit:
+		values := make(TupleValue, len(e.Elems))
+		for i, elem := range e.Elems {
+			v, m := untypedValue(elem, names)
+			if m != nil {
+				return nil, m
+			}
+			values[i] = v
+		}
+		return values, nil
+
+	case *ast.Ident:
+		if names {
+			return Name(e.Name), nil
+		}
+		return nil, mismatchf(e, "cannot use %s where no enum is expected", e.Name)
+	}
+
+	return nil, mismatchf(e, "unexpected expression %T", e)
+}
+
+// returns the value of a literal. The lexer only produces
+// well formed literals, so only integers that overflow
+// int64 are reported.
+func basicValue(lit *ast.BasicLit) (Value, *mismatch) {
+	switch lit.Kind {
+	case token.TokenIntegerLiteral:
+		base, digits := 10, strings.Replace(lit.Value, "_", "", -1)
+		if strings.ContainsAny(digits, "xX") {
+			// base 0 reads the 0x prefix, but would read
+			// decimals with a leading 0 as octal
+			base = 0
+		}
+
+		v, err := strconv.ParseInt(digits, base, 64)
+		if err != nil {
+			return nil, mismatchf(lit, "integer literal %s overflows integer", lit.Value)
+		}
+		return v, nil
+
+	case token.TokenFloatLiteral:
+		v, err := strconv.ParseFloat(strings.Replace(lit.Value, "_", "", -1), 64)
+		if err != nil {
+			return nil, mismatchf(lit, "float literal %s is out of range", lit.Value)
+		}
+		return v, nil
+
+	case token.TokenStringLiteral:
+		v, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return nil, mismatchf(lit, "invalid string literal %s", lit.Value)
+		}
+		return v, nil
+
+	case token.TokenTrue:
+		return true, nil
+
+	case token.TokenFalse:
+		return false, nil
+	}
+
+	return nil, mismatchf(lit, "unexpected literal %s", lit.Value)
+}
+
+// returns e in meme syntax
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return e.Value
+	case *ast.Ident:
+		return e.Name
+	case *ast.ListLit:
+		return "[" + exprListString(e.Elems) + "]"
+	case *ast.TupleLit:
+		return "(" + exprListString(e.Elems) + ")"
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+func exprListString(list []ast.Expr) string {
+	s := make([]string, len(list))
+	for i, e := range list {
+		s[i] = exprString(e)
+	}
+	return strings.Join(s, ", ")
+}
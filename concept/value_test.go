@@ -0,0 +1,108 @@
+package concept
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Default values", func() {
+	Context("Resolving valid defaults", func() {
+		It("Should resolve defaults of every type", func() {
+			tree, err := resolve(parseSources(
+				`concept Task {
+					optional isDone boolean = false
+					optional priority integer = -3
+					optional mask integer = 0xFF_FF
+					optional title string = "untitled\t(draft)"
+					optional notes string = ` + "`line\\n`" + `
+					optional tags [string] = ["a", "b"]
+					optional empty [integer] = []
+					optional pair (integer, string) = (1, "one")
+					optional either oneof(integer, string) = "two"
+					optional anything Concept = [1.5, true]
+					optional plain integer
+				}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			defaults := make(map[string]Value)
+			for _, f := range tree.Lookup("Task").Fields() {
+				defaults[f.Name()] = f.Default()
+			}
+			Expect(defaults).To(Equal(map[string]Value{
+				"isDone":   false,
+				"priority": int64(-3),
+				"mask":     int64(0xFFFF),
+				"title":    "untitled\t(draft)",
+				"notes":    `line\n`,
+				"tags":     ListValue{"a", "b"},
+				"empty":    ListValue{},
+				"pair":     TupleValue{int64(1), "one"},
+				"either":   "two",
+				"anything": ListValue{1.5, true},
+				"plain":    nil,
+			}))
+		})
+
+		It("Should inherit defaults, also through generic concepts", func() {
+			tree, err := resolve(parseSources(
+				`concept Item { optional isDone boolean = false }
+				 concept Chore extends Item { required room string }
+				 concept Box<T> { optional items [T] optional count integer = 0 }
+				 concept Boxes extends Box<Item> {}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree.Lookup("Chore").Describe()).To(Equal(
+				"concept Chore extends Item {\n" +
+					"\toptional isDone boolean = false // from Item\n" +
+					"\trequired room string\n" +
+					"}"))
+
+			count := tree.Lookup("Boxes").LookupField("count")
+			Expect(count.Default()).To(Equal(int64(0)))
+		})
+
+		It("Should format values in meme syntax", func() {
+			Expect(FormatValue(ListValue{int64(1), 2.0, "a\"b", TupleValue{true, 1e21}})).
+				To(Equal(`[1, 2.0, "a\"b", (true, 1e+21)]`))
+		})
+	})
+
+	Context("Checking defaults against their types", func() {
+		It("Should report values of the wrong type", func() {
+			_, err := resolve(parseSources(
+				`concept Task {
+					optional priority integer = "high"
+					optional ratio integer = 0.5
+					optional tags [string] = ["a", 2]
+					optional pair (integer, string) = (1, 2, 3)
+					optional either oneof(integer, boolean) = "no"
+					optional owner Task = "me"
+					optional big integer = 9223372036854775808
+				}`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:2:34: invalid default value for field `priority`: cannot use \"high\" as a value of type `integer`",
+				"a.meme:3:31: invalid default value for field `ratio`: cannot use 0.5 as a value of type `integer`",
+				"a.meme:4:37: invalid default value for field `tags`: cannot use 2 as a value of type `string`",
+				"a.meme:5:40: invalid default value for field `pair`: cannot use (1, 2, 3) as a value of type `(integer, string)`",
+				"a.meme:6:48: invalid default value for field `either`: cannot use \"no\" as a value of type `oneof(integer, boolean)`",
+				"a.meme:7:28: invalid default value for field `owner`: a value of concept type `Task` cannot be written as a literal",
+				"a.meme:8:29: invalid default value for field `big`: integer literal 9223372036854775808 overflows integer",
+			}, "\n")))
+		})
+
+		It("Should reject defaults of required fields and of type parameters", func() {
+			_, err := resolve(parseSources(
+				`concept Task { required isDone boolean = false }
+				 concept Box<T> { optional item T = 1 }`,
+			))
+			Expect(err).To(MatchError(strings.Join([]string{
+				"a.meme:1:42: required field `isDone` cannot have a default value",
+				"a.meme:2:41: invalid default value for field `item`: a value of type parameter type `T` cannot be written as a literal",
+			}, "\n")))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package diag
+
+// Code identifies a kind of diagnostic. Codes are stable and
+// may be used to look up or filter diagnostics.
+type Code string
+
+// lexical errors
+const (
+	CodeLexical Code = "E0100" // the input could not be tokenized
+)
+
+// syntax errors
+const (
+	CodeUnexpectedToken Code = "E0200" // a token that does not fit the grammar
+	CodeShortTuple      Code = "E0201" // a tuple with fewer than two elements
+)
+
+// semantic errors
+const (
+	CodeUndefinedName           Code = "E0300" // a name that is not a concept, an enum or a type parameter in scope
+	CodeRedeclaredConcept       Code = "E0301" // two concepts with the same name
+	CodeRedeclaredField         Code = "E0302" // two fields with the same name in one concept
+	CodeRedeclaredTypeParam     Code = "E0303" // two type parameters with the same name
+	CodeInvalidExtends          Code = "E0304" // an extends clause that cannot be honoured
+	CodeInheritanceCycle        Code = "E0305" // a concept that (indirectly) extends itself
+	CodeWidenedField            Code = "E0306" // a required field redeclared as optional
+	CodeIncompatibleField       Code = "E0307" // a redeclared field whose type is not a subtype
+	CodeTypeArgumentCount       Code = "E0308" // the wrong number of type arguments
+	CodeTypeParamWithArguments  Code = "E0309" // type arguments given to a type parameter
+	CodeInvalidDefault          Code = "E0310" // a default value that does not match the type of its field
+	CodeRequiredDefault         Code = "E0311" // a default value given to a required field
+	CodeInvalidAnnotation       Code = "E0312" // a built-in annotation with invalid arguments
+	CodeMisplacedAnnotation     Code = "E0313" // a built-in annotation on a declaration it does not apply to
+	CodeDuplicateAnnotation     Code = "E0314" // a built-in annotation given twice
+	CodeRedeclaredEnum          Code = "E0315" // an enum with the name of another enum or a concept
+	CodeRedeclaredMember        Code = "E0316" // two members of an enum with the same name or value
+	CodeInvalidEnumValue        Code = "E0317" // an enum member value that is not a literal of the kind of the enum
+	CodeNotImported             Code = "E0318" // a name declared in a file that is not imported
+	CodeUndefinedPackage        Code = "E0319" // a qualifier that is neither an imported package nor an alias
+	CodeAmbiguousImport         Code = "E0320" // an import alias declared twice or standing for several packages
+	CodeInvalidRelation         Code = "E0321" // a relation whose ends are not concepts
+	CodeInvalidRelationProperty Code = "E0322" // a relation property that does not fit the ends of the relation
+)
+
+// loading errors
+const (
+	CodeImportNotFound Code = "E0400" // an import or a file that cannot be read
+	CodeImportCycle    Code = "E0401" // files that (indirectly) import themselves
+)
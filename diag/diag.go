@@ -0,0 +1,172 @@
+// Package diag implements the diagnostics reported by the
+// lexer, the parser and the resolver, and renders them in
+// the compiler style, with the offending source line and a
+// caret underline.
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+var severityString = map[Severity]string{
+	Error:   "error",
+	Warning: "warning",
+	Note:    "note",
+}
+
+func (severity Severity) String() string {
+	return severityString[severity]
+}
+
+// Span is a range of source text, from Pos up to End.
+type Span struct {
+	Pos token.Pos
+	End token.Pos // position just past the end of the span
+}
+
+// Node is implemented by the nodes of the syntax tree.
+type Node interface {
+	Pos() token.Pos
+	End() token.Pos
+}
+
+// SpanOf returns the span covered by node n.
+func SpanOf(n Node) Span {
+	return Span{Pos: n.Pos(), End: n.End()}
+}
+
+// TokenSpan returns the span covered by token t. Empty
+// tokens, e.g. EOF, cover a single character.
+func TokenSpan(t token.Token) Span {
+	if end := t.End(); end > t.Pos {
+		return Span{Pos: t.Pos, End: end}
+	}
+	return Span{Pos: t.Pos, End: t.Pos + 1}
+}
+
+// Label is a span of source text with an optional message
+// explaining its role in a diagnostic.
+type Label struct {
+	Span    Span
+	Message string
+}
+
+type Diagnostic struct {
+	Fset      *token.FileSet // the set the spans of the diagnostic refer to
+	Severity  Severity
+	Code      Code
+	Message   string
+	Primary   Label   // where the problem is
+	Secondary []Label // related locations, e.g. a previous declaration
+	Notes     []string
+}
+
+// Errorf returns an error diagnostic at span, a span of a
+// file in fset.
+func Errorf(fset *token.FileSet, span Span, code Code, format string, args ...interface{}) *Diagnostic {
+	return &Diagnostic{
+		Fset:     fset,
+		Severity: Error,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Primary:  Label{Span: span},
+	}
+}
+
+// WithLabel sets the message shown under the primary span.
+func (d *Diagnostic) WithLabel(format string, args ...interface{}) *Diagnostic {
+	d.Primary.Message = fmt.Sprintf(format, args...)
+	return d
+}
+
+// WithSecondary adds a related location to the diagnostic.
+func (d *Diagnostic) WithSecondary(span Span, format string, args ...interface{}) *Diagnostic {
+	d.Secondary = append(d.Secondary, Label{Span: span, Message: fmt.Sprintf(format, args...)})
+	return d
+}
+
+// WithNote adds a note shown after the source excerpts.
+func (d *Diagnostic) WithNote(format string, args ...interface{}) *Diagnostic {
+	d.Notes = append(d.Notes, fmt.Sprintf(format, args...))
+	return d
+}
+
+// Position returns the position the primary span starts at.
+func (d *Diagnostic) Position() token.Position {
+	return d.position(d.Primary.Span.Pos)
+}
+
+func (d *Diagnostic) position(p token.Pos) token.Position {
+	if d.Fset == nil {
+		return token.Position{}
+	}
+	return d.Fset.Position(p)
+}
+
+// Error returns the diagnostic on a single line, prefixed
+// with its location.
+func (d *Diagnostic) Error() string {
+	if pos := d.Position(); pos.IsValid() {
+		return fmt.Sprintf("%s: %s", pos, d.Message)
+	}
+	return d.Message
+}
+
+// List is a list of diagnostics. A List with at least one
+// error severity diagnostic is returned as the error of
+// the functions that produce it.
+type List []*Diagnostic
+
+func (list *List) Add(d *Diagnostic) {
+	*list = append(*list, d)
+}
+
+func (list List) Error() string {
+	s := make([]string, len(list))
+	for i, d := range list {
+		s[i] = d.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// HasErrors reports whether list contains a diagnostic of
+// error severity.
+func (list List) HasErrors() bool {
+	for _, d := range list {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns list as an error if it has errors, nil otherwise.
+func (list List) Err() error {
+	if !list.HasErrors() {
+		return nil
+	}
+	return list
+}
+
+// Sort sorts the list by file and by position in the file.
+func (list List) Sort() {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i].Position(), list[j].Position()
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+}
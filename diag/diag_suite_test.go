@@ -0,0 +1,13 @@
+package diag_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiag(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diag Suite")
+}
@@ -0,0 +1,66 @@
+package diag
+
+import (
+	"encoding/json"
+)
+
+// the JSON form of a label, with its span as lines and
+// columns
+type jsonLabel struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Message   string `json:"message,omitempty"`
+}
+
+// the JSON form of a diagnostic
+type jsonDiagnostic struct {
+	Severity  string      `json:"severity"`
+	Code      Code        `json:"code"`
+	Message   string      `json:"message"`
+	Primary   *jsonLabel  `json:"primary,omitempty"` // nil if it is not about a location in a file
+	Secondary []jsonLabel `json:"secondary,omitempty"`
+	Notes     []string    `json:"notes,omitempty"`
+}
+
+// MarshalJSON encodes the diagnostic as an object with its
+// severity, code and message, and with its labels as
+// lines and columns, e.g.
+//
+//	{
+//		"severity": "error",
+//		"code": "E0300",
+//		"message": "undefined name `Isssue`",
+//		"primary": {"file": "board.meme", "line": 3, "column": 19, "endLine": 3, "endColumn": 25}
+//	}
+func (d *Diagnostic) MarshalJSON() ([]byte, error) {
+	out := jsonDiagnostic{
+		Severity: d.Severity.String(),
+		Code:     d.Code,
+		Message:  d.Message,
+		Notes:    d.Notes,
+	}
+	if d.Position().IsValid() {
+		primary := d.label(d.Primary)
+		out.Primary = &primary
+	}
+	for _, label := range d.Secondary {
+		out.Secondary = append(out.Secondary, d.label(label))
+	}
+	return json.Marshal(out)
+}
+
+// returns the JSON form of label, a label of d
+func (d *Diagnostic) label(label Label) jsonLabel {
+	pos, end := d.position(label.Span.Pos), d.position(label.Span.End)
+	return jsonLabel{
+		File:      pos.Filename,
+		Line:      pos.Line,
+		Column:    pos.Column,
+		EndLine:   end.Line,
+		EndColumn: end.Column,
+		Message:   label.Message,
+	}
+}
@@ -0,0 +1,45 @@
+package diag
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+var _ = Describe("JSON", func() {
+	fset := token.NewFileSet()
+	issue := addFile(fset, "issue.meme", "concept Issue {}\nconcept Issue {}\n")
+
+	It("Should encode diagnostics with their locations as lines and columns", func() {
+		list := List{
+			Errorf(fset, span(issue, 25, 5), CodeRedeclaredConcept, "concept `Issue` redeclared").
+				WithLabel("redeclared here").
+				WithSecondary(span(issue, 8, 5), "previous declaration").
+				WithNote("concept names must be unique"),
+			Errorf(nil, Span{}, CodeImportNotFound, "cannot load missing.meme: no such file or directory"),
+		}
+
+		out, err := json.Marshal(list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(`[
+			{
+				"severity": "error",
+				"code": "E0301",
+				"message": "concept ` + "`Issue`" + ` redeclared",
+				"primary": {"file": "issue.meme", "line": 2, "column": 9, "endLine": 2, "endColumn": 14, "message": "redeclared here"},
+				"secondary": [
+					{"file": "issue.meme", "line": 1, "column": 9, "endLine": 1, "endColumn": 14, "message": "previous declaration"}
+				],
+				"notes": ["concept names must be unique"]
+			},
+			{
+				"severity": "error",
+				"code": "E0400",
+				"message": "cannot load missing.meme: no such file or directory"
+			}
+		]`))
+	})
+})
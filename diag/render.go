@@ -0,0 +1,146 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// Sources maps file names to their contents. It is used to
+// print the source excerpts of diagnostics.
+type Sources map[string]string
+
+// Render writes every diagnostic in list to w, separated by
+// blank lines.
+func (list List) Render(w io.Writer, sources Sources) {
+	for i, d := range list {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		d.Render(w, sources)
+	}
+}
+
+// Render writes the diagnostic to w in the compiler style:
+//
+//	error[E0300]: undefined name `Isssue`
+//	 --> examples/scrum_board/board.meme:3:19
+//	  |
+//	3 |     required issues [Isssue]
+//	  |                      ^^^^^^
+//	  = note: ...
+//
+// Secondary labels are underlined with dashes. If the source
+// of a file is not in sources, only the location is printed.
+// A diagnostic without a code is printed as "error: ...".
+func (d *Diagnostic) Render(w io.Writer, sources Sources) {
+	labels := append([]Label{d.Primary}, d.Secondary...)
+
+	// width of the line number gutter
+	width := 0
+	for _, label := range labels {
+		if n := len(strconv.Itoa(d.position(label.Span.Pos).Line)); n > width {
+			width = n
+		}
+	}
+	gutter := strings.Repeat(" ", width)
+
+	if d.Code == "" {
+		fmt.Fprintf(w, "%s: %s\n", d.Severity, d.Message)
+	} else {
+		fmt.Fprintf(w, "%s[%s]: %s\n", d.Severity, d.Code, d.Message)
+	}
+
+	for i, label := range labels {
+		arrow, underline := "-->", "^"
+		if i > 0 {
+			arrow, underline = ":::", "-"
+		}
+
+		pos, end := d.position(label.Span.Pos), d.position(label.Span.End)
+		line, ok := excerpt(sources, pos)
+		name := pos.Filename
+		if name == "" {
+			name = "<input>"
+		}
+
+		if !ok && !pos.IsValid() {
+			// not about a location in a file, e.g. a file
+			// that cannot be read
+			continue
+		}
+		if !ok {
+			fmt.Fprintf(w, "%s%s %s:%d:%d\n", gutter, arrow, name, pos.Line, pos.Column)
+			if label.Message != "" {
+				fmt.Fprintf(w, "%s = %s\n", gutter, label.Message)
+			}
+			continue
+		}
+
+		// columns are counted in characters
+		chars := []rune(line)
+		column := pos.Column
+		if column < 1 || column > len(chars)+1 {
+			// the span does not start on this line, point at
+			// the start of the line instead of guessing
+			column = 1
+		}
+
+		fmt.Fprintf(w, "%s%s %s:%d:%d\n", gutter, arrow, name, pos.Line, column)
+		fmt.Fprintf(w, "%s |\n", gutter)
+		fmt.Fprintf(w, "%*d | %s\n", width, pos.Line, line)
+
+		// keep tabs so that the underline lines up with the
+		// source line however the terminal expands them
+		indent := append([]rune{}, chars[:column-1]...)
+		for j, r := range indent {
+			if r != '\t' {
+				indent[j] = ' '
+			}
+		}
+
+		// spans ending on a later line are underlined up to
+		// the end of their first line
+		length := len(chars) - (column - 1)
+		if end.Line == pos.Line {
+			length = end.Column - column
+		}
+		if max := len(chars) - (column - 1); length > max {
+			length = max
+		}
+		if length < 1 {
+			length = 1
+		}
+
+		marker := strings.TrimRight(fmt.Sprintf("%s %s", strings.Repeat(underline, length), label.Message), " ")
+		fmt.Fprintf(w, "%s | %s%s\n", gutter, string(indent), marker)
+	}
+
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s = note: %s\n", gutter, note)
+	}
+}
+
+// returns the source line pos is on
+func excerpt(sources Sources, pos token.Position) (line string, ok bool) {
+	src, ok := sources[pos.Filename]
+	if !ok || !pos.IsValid() || pos.Offset > len(src) {
+		return "", false
+	}
+
+	// lines end at "\n", "\r\n" or a lone "\r"
+	start := strings.LastIndexAny(src[:pos.Offset], "\r\n") + 1
+
+	end := strings.IndexAny(src[start:], "\r\n")
+	if end < 0 {
+		end = len(src)
+	} else {
+		end += start
+	}
+
+	// a byte order mark is not part of the first line
+	return strings.TrimPrefix(src[start:end], "\uFEFF"), true
+}
@@ -0,0 +1,132 @@
+package diag
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// adds a file with the given contents to fset, with its lines
+func addFile(fset *token.FileSet, name string, src string) *token.File {
+	f := fset.AddFile(name, name, len(src))
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			f.AddLine(i + 1)
+		}
+	}
+	return f
+}
+
+// returns the span of length characters at offset in f
+func span(f *token.File, offset int, length int) Span {
+	return Span{Pos: f.Pos(offset), End: f.Pos(offset + length)}
+}
+
+var _ = Describe("Render", func() {
+	sources := Sources{
+		"board.meme": "concept Board {\n\trequired issues [Isssue]\n}\n",
+		"issue.meme": "concept Issue {}\r\nconcept Issue {}\r\n",
+		"größe.meme": "concept Größe { required 名前 Wert }\n",
+	}
+
+	fset := token.NewFileSet()
+	board := addFile(fset, "board.meme", sources["board.meme"])
+	issue := addFile(fset, "issue.meme", sources["issue.meme"])
+	missing := addFile(fset, "missing.meme", strings.Repeat("\n", 11)+"  x")
+
+	render := func(d *Diagnostic) string {
+		var buf bytes.Buffer
+		d.Render(&buf, sources)
+		return buf.String()
+	}
+
+	It("Should underline the primary span", func() {
+		d := Errorf(fset, span(board, 34, 6), CodeUndefinedName, "undefined name `Isssue`")
+		Expect(render(d)).To(Equal(strings.Join([]string{
+			"error[E0300]: undefined name `Isssue`",
+			" --> board.meme:2:19",
+			"  |",
+			"2 | \trequired issues [Isssue]",
+			"  | \t                 ^^^^^^",
+			"",
+		}, "\n")))
+	})
+
+	It("Should render labels, secondary spans and notes", func() {
+		d := Errorf(fset, span(issue, 26, 5), CodeRedeclaredConcept, "concept `Issue` redeclared").
+			WithLabel("redeclared here").
+			WithSecondary(span(issue, 8, 5), "previous declaration").
+			WithNote("concept names must be unique")
+		Expect(render(d)).To(Equal(strings.Join([]string{
+			"error[E0301]: concept `Issue` redeclared",
+			" --> issue.meme:2:9",
+			"  |",
+			"2 | concept Issue {}",
+			"  |         ^^^^^ redeclared here",
+			" ::: issue.meme:1:9",
+			"  |",
+			"1 | concept Issue {}",
+			"  |         ----- previous declaration",
+			"  = note: concept names must be unique",
+			"",
+		}, "\n")))
+	})
+
+	It("Should count columns in characters", func() {
+		src := sources["größe.meme"]
+		f := addFile(fset, "größe.meme", src)
+		f.SetSource(src)
+
+		d := Errorf(fset, span(f, strings.Index(src, "Wert"), 4), CodeUndefinedName, "undefined name `Wert`")
+		Expect(render(d)).To(Equal(strings.Join([]string{
+			"error[E0300]: undefined name `Wert`",
+			" --> größe.meme:1:29",
+			"  |",
+			"1 | concept Größe { required 名前 Wert }",
+			"  |                             ^^^^",
+			"",
+		}, "\n")))
+	})
+
+	It("Should print only the location if the source is unknown", func() {
+		d := Errorf(fset, span(missing, 13, 1), CodeLexical, "Syntax Error")
+		Expect(render(d)).To(Equal("error[E0100]: Syntax Error\n  --> missing.meme:12:3\n"))
+	})
+
+	It("Should leave out a missing code and location", func() {
+		d := Errorf(nil, Span{}, "", "open meme.yaml: permission denied")
+		Expect(render(d)).To(Equal("error: open meme.yaml: permission denied\n"))
+	})
+})
+
+var _ = Describe("List", func() {
+	It("Should sort by file, line and offset", func() {
+		fset := token.NewFileSet()
+		b := addFile(fset, "b.meme", "concept B {}")
+		a := addFile(fset, "a.meme", "concept A {\n\trequired aaa A\n}")
+
+		list := List{
+			Errorf(fset, span(b, 0, 1), CodeLexical, "3"),
+			Errorf(fset, span(a, 26, 1), CodeLexical, "2"),
+			Errorf(fset, span(a, 23, 1), CodeLexical, "1"),
+		}
+		list.Sort()
+		Expect(list.Error()).To(Equal("a.meme:2:12: 1\na.meme:2:15: 2\nb.meme:1:1: 3"))
+	})
+
+	It("Should only be an error if it contains errors", func() {
+		list := List{}
+		Expect(list.Err()).To(BeNil())
+
+		list.Add(&Diagnostic{Severity: Warning, Message: "careful"})
+		Expect(list.HasErrors()).To(BeFalse())
+		Expect(list.Err()).To(BeNil())
+
+		list.Add(Errorf(nil, Span{}, CodeLexical, "broken"))
+		Expect(list.Err()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,11 @@
+// Package examples holds the example projects, which meme
+// init starts new projects from.
+package examples
+
+import "embed"
+
+// FS holds the files of every example, under the directory
+// of the example, e.g. todolist/todolist.meme.
+//
+//go:embed todolist scrum_board
+var FS embed.FS
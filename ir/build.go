@@ -0,0 +1,310 @@
+package ir
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/concept"
+	"github.com/riyanshkarani011235/meme/loader"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// Config holds the options of Build.
+type Config struct {
+	// Root is the directory the files of positions are named
+	// relative to, usually the one of the manifest of the
+	// project. If it is empty, it is the deepest directory
+	// holding every file of the tree outside the builtin
+	// library.
+	Root string
+}
+
+// Build returns the IR of a resolved concept tree with the
+// default options. See Config.Build.
+func Build(tree *concept.ConceptTree, fset *token.FileSet) *Schema {
+	return Config{}.Build(tree, fset)
+}
+
+// Build returns the IR of a resolved concept tree. fset is
+// the set of the files the tree was resolved from, which
+// gives the positions of the declarations. The files of the
+// positions are named relative to the root, with slashes,
+// and the files of the builtin library of the tree as they
+// are imported, e.g. builtin/list.meme, so that the IR does
+// not depend on the directory it is built from.
+func (c Config) Build(tree *concept.ConceptTree, fset *token.FileSet) *Schema {
+	b := &builder{fset: fset, builtin: absolute(tree.Builtin()), root: absolute(c.Root)}
+	if c.Root == "" {
+		b.root = b.commonDir(tree)
+	}
+	schema := &Schema{
+		Version:   Version,
+		Concepts:  make([]*Concept, 0),
+		Enums:     make([]*Enum, 0),
+		Relations: make([]*Relation, 0),
+	}
+
+	for _, c := range tree.Concepts() {
+		schema.Concepts = append(schema.Concepts, b.concept(c))
+	}
+	for _, e := range tree.Enums() {
+		schema.Enums = append(schema.Enums, b.enum(e))
+	}
+	for _, rel := range tree.Relations() {
+		schema.Relations = append(schema.Relations, b.relation(rel))
+	}
+	return schema
+}
+
+type builder struct {
+	fset    *token.FileSet
+	root    string // absolute, the directory files are named relative to
+	builtin string // absolute, "" without a builtin library
+}
+
+// returns the deepest directory holding every file of the
+// tree outside the builtin library, or "" if there is none
+func (b *builder) commonDir(tree *concept.ConceptTree) string {
+	files := make([]string, 0)
+	for _, c := range tree.Concepts() {
+		files = append(files, c.File())
+	}
+	for _, e := range tree.Enums() {
+		files = append(files, e.File())
+	}
+
+	common := ""
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		dir := filepath.Dir(absolute(file))
+		if _, ok := within(b.builtin, dir); ok {
+			continue
+		}
+		for common != "" {
+			if _, ok := within(common, dir); ok {
+				break
+			}
+			common = filepath.Dir(common)
+		}
+		if common == "" {
+			common = dir
+		}
+	}
+	return common
+}
+
+// returns the name of the file at path in the IR
+func (b *builder) fileName(path string) string {
+	abs := absolute(path)
+	if rel, ok := within(b.builtin, abs); ok {
+		return loader.BuiltinPrefix + rel
+	}
+	if rel, ok := within(b.root, abs); ok {
+		return rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// returns the path of path inside dir with slashes, and
+// whether path is inside dir
+func within(dir string, path string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// returns the absolute form of path, or path if it has none
+func absolute(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func (b *builder) concept(c *concept.Concept) *Concept {
+	out := &Concept{
+		Name:        c.QualifiedName(),
+		Package:     c.Package(),
+		Doc:         c.Doc(),
+		Annotations: annotations(c.Annotations()),
+	}
+	for _, param := range c.TypeParams() {
+		out.TypeParams = append(out.TypeParams, param.Name)
+	}
+	if c.Extends() != nil {
+		out.Extends = typeOf(c.Extends())
+	}
+	for _, f := range c.AllFields() {
+		out.Fields = append(out.Fields, b.field(f))
+	}
+	if d := c.Decl(); d != nil {
+		out.Position = b.position(d.Name.Pos())
+	}
+	return out
+}
+
+func (b *builder) field(f *concept.Field) *Field {
+	out := &Field{
+		Name:        f.Name(),
+		Required:    f.Required(),
+		Type:        typeOf(f.Type()),
+		Doc:         f.Doc(),
+		Annotations: annotations(f.Annotations()),
+		Owner:       f.Owner().QualifiedName(),
+	}
+	if f.HasDefault() {
+		out.Default = value(f.Default())
+	}
+	if d := f.Decl(); d != nil {
+		out.Position = b.position(d.Name.Pos())
+	}
+	return out
+}
+
+func (b *builder) enum(e *concept.Enum) *Enum {
+	out := &Enum{
+		Name:        e.QualifiedName(),
+		Package:     e.Package(),
+		Doc:         e.Doc(),
+		Annotations: annotations(e.Annotations()),
+		Members:     make([]*EnumMember, 0, len(e.Members())),
+		Position:    b.position(e.Decl().Name.Pos()),
+	}
+	for _, m := range e.Members() {
+		out.Members = append(out.Members, &EnumMember{
+			Name:     m.Name(),
+			Value:    value(m.Value()),
+			Doc:      m.Doc(),
+			Position: b.position(m.Decl().Name.Pos()),
+		})
+	}
+	return out
+}
+
+func (b *builder) relation(rel *concept.Relation) *Relation {
+	out := &Relation{
+		Name:       rel.QualifiedName(),
+		From:       relationEnd(rel.From()),
+		To:         relationEnd(rel.To()),
+		Symmetric:  rel.Symmetric(),
+		Transitive: rel.Transitive(),
+		Acyclic:    rel.Acyclic(),
+	}
+	if inverse := rel.Inverse(); inverse != nil {
+		out.Inverse = inverse.QualifiedName()
+	}
+	if d := rel.Decl(); d != nil {
+		out.Position = b.position(d.Name.Pos())
+	}
+	return out
+}
+
+// returns the position of pos, or nil if pos is not in a file
+// of the set
+func (b *builder) position(pos token.Pos) *Position {
+	if b.fset == nil {
+		return nil
+	}
+	p := b.fset.Position(pos)
+	if !p.IsValid() {
+		return nil
+	}
+	return &Position{File: b.fileName(p.Filename), Line: p.Line, Column: p.Column}
+}
+
+func relationEnd(e *concept.RelationEnd) *RelationEnd {
+	out := &RelationEnd{Cardinality: e.Cardinality.String()}
+	if e.Concept != nil {
+		out.Concept = typeOf(e.Concept)
+	}
+	return out
+}
+
+func typeOf(t concept.Type) *Type {
+	switch t := t.(type) {
+	case *concept.PrimitiveType:
+		return &Type{Kind: KindPrimitive, Name: t.Kind.String()}
+	case *concept.ConceptType:
+		return &Type{Kind: KindConcept, Name: t.Concept.QualifiedName(), Args: typeList(t.Args)}
+	case *concept.EnumType:
+		return &Type{Kind: KindEnum, Name: t.Enum.QualifiedName()}
+	case *concept.TypeParamType:
+		return &Type{Kind: KindTypeParam, Name: t.Param.Name}
+	case *concept.ListType:
+		return &Type{Kind: KindList, Elem: typeOf(t.Elem)}
+	case *concept.TupleType:
+		return &Type{Kind: KindTuple, Elems: typeList(t.Elems)}
+	case *concept.OneOfType:
+		return &Type{Kind: KindOneOf, Elems: typeList(t.Options)}
+	case *concept.AnyOfType:
+		return &Type{Kind: KindAnyOf, Elems: typeList(t.Options)}
+	}
+	return nil
+}
+
+func typeList(list []concept.Type) []*Type {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make([]*Type, len(list))
+	for i, t := range list {
+		out[i] = typeOf(t)
+	}
+	return out
+}
+
+func annotations(list []*concept.Annotation) []*Annotation {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make([]*Annotation, len(list))
+	for i, a := range list {
+		out[i] = &Annotation{Name: a.Name}
+		for _, arg := range a.Args {
+			out[i].Args = append(out[i].Args, value(arg))
+		}
+	}
+	return out
+}
+
+// returns v as it is read back from JSON: numbers are
+// json.Number, lists and tuples []interface{}, and enum
+// members and names objects
+func value(v concept.Value) interface{} {
+	switch v := v.(type) {
+	case *concept.EnumMember:
+		return map[string]interface{}{"enum": v.Enum().QualifiedName(), "member": v.Name()}
+	case concept.Name:
+		return map[string]interface{}{"ident": string(v)}
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case float64:
+		// formatted as in meme, so that it stays a float
+		return json.Number(concept.FormatValue(v))
+	case concept.ListValue:
+		return valueList(v)
+	case concept.TupleValue:
+		return valueList(v)
+	}
+	return v
+}
+
+func valueList(list []concept.Value) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, v := range list {
+		out[i] = value(v)
+	}
+	return out
+}
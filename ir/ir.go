@@ -0,0 +1,212 @@
+// Package ir holds the intermediate representation of a
+// resolved schema: every concept with its inherited fields
+// and instantiated generics, every enum and every relation,
+// with their annotations, doc comments and source positions.
+// It only depends on names, so that it can be written as
+// JSON and read back by tools that know nothing of the
+// syntax tree or of the concept package.
+//
+// The JSON form of a schema is an object with the IR version
+// and a list of each kind of declaration, sorted by qualified
+// name:
+//
+//	{
+//		"version": 1,
+//		"concepts": [{
+//			"name": "scrum.Issue",
+//			"package": "scrum",
+//			"doc": "a unit of work",
+//			"extends": {"kind": "concept", "name": "Concept"},
+//			"fields": [{
+//				"name": "title",
+//				"required": true,
+//				"type": {"kind": "primitive", "name": "string"},
+//				"owner": "scrum.Issue",
+//				"position": {"file": "issue.meme", "line": 4, "column": 2}
+//			}],
+//			"position": {"file": "issue.meme", "line": 3, "column": 1}
+//		}],
+//		"enums": [...],
+//		"relations": [...]
+//	}
+//
+// Values, such as default values and the arguments of
+// annotations, are JSON values: numbers, strings, booleans
+// and lists, with tuples as lists. Members of enums, and
+// the identifiers written as arguments of annotations that
+// are not built in, are objects so that they are not
+// mistaken for strings:
+//
+//	{"enum": "scrum.Status", "member": "Open"}
+//	{"ident": "name"}
+//
+// Fields that are empty, false or zero are left out. The
+// version changes whenever the shape changes in a way that
+// readers of an older version would misread. New fields may
+// be added without changing it, and are ignored by older
+// readers.
+package ir
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Version is the version of the shape of the IR written by
+// this package, and the only one it reads.
+const Version = 1
+
+// Schema is a resolved schema.
+type Schema struct {
+	Version   int         `json:"version"`
+	Concepts  []*Concept  `json:"concepts"`
+	Enums     []*Enum     `json:"enums"`
+	Relations []*Relation `json:"relations"`
+}
+
+// Position is the location of a declaration in its file.
+// Lines and columns start at 1, and columns are counted in
+// characters. The file is named relative to the root of the
+// project, with slashes, or as it is imported if it is in
+// the builtin library, e.g. builtin/list.meme.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Concept is a concept with every field it contains,
+// inherited ones included, with the type arguments of its
+// ancestors substituted. Relations are concepts too, whose
+// first fields are their ends, from and to.
+type Concept struct {
+	Name        string        `json:"name"` // the qualified name
+	Package     string        `json:"package,omitempty"`
+	Doc         string        `json:"doc,omitempty"`
+	Annotations []*Annotation `json:"annotations,omitempty"`
+	TypeParams  []string      `json:"typeParams,omitempty"`
+	Extends     *Type         `json:"extends,omitempty"` // the instantiated parent, nil for the root concept
+	Fields      []*Field      `json:"fields,omitempty"`
+	Position    *Position     `json:"position,omitempty"` // nil if the concept is not declared in a file
+}
+
+// Field is a field of a concept.
+type Field struct {
+	Name        string        `json:"name"`
+	Required    bool          `json:"required,omitempty"`
+	Type        *Type         `json:"type"`
+	Default     interface{}   `json:"default,omitempty"` // a value, nil if the field has no default value
+	Doc         string        `json:"doc,omitempty"`
+	Annotations []*Annotation `json:"annotations,omitempty"`
+	Owner       string        `json:"owner"` // the concept that declares the field
+	Position    *Position     `json:"position,omitempty"`
+}
+
+// the kinds of types
+const (
+	KindPrimitive = "primitive" // integer, string or boolean, the Name of the type
+	KindConcept   = "concept"   // the concept Name, instantiated with Args
+	KindEnum      = "enum"      // the enum Name
+	KindTypeParam = "typeParam" // the type parameter Name of the concept it is used in
+	KindList      = "list"      // a list of Elem
+	KindTuple     = "tuple"     // a tuple of Elems
+	KindOneOf     = "oneof"     // a value of one of Elems
+	KindAnyOf     = "anyof"     // a value of any of Elems
+)
+
+// Type is a resolved type expression.
+type Type struct {
+	Kind  string  `json:"kind"`
+	Name  string  `json:"name,omitempty"`
+	Args  []*Type `json:"args,omitempty"`
+	Elem  *Type   `json:"elem,omitempty"`
+	Elems []*Type `json:"elems,omitempty"`
+}
+
+// Annotation is an annotation with its arguments as values.
+type Annotation struct {
+	Name string        `json:"name"`
+	Args []interface{} `json:"args,omitempty"`
+}
+
+// Enum is an enum with its members.
+type Enum struct {
+	Name        string        `json:"name"`
+	Package     string        `json:"package,omitempty"`
+	Doc         string        `json:"doc,omitempty"`
+	Annotations []*Annotation `json:"annotations,omitempty"`
+	Members     []*EnumMember `json:"members"`
+	Position    *Position     `json:"position,omitempty"`
+}
+
+// EnumMember is a member of an enum with its value, a string
+// or an integer.
+type EnumMember struct {
+	Name     string      `json:"name"`
+	Value    interface{} `json:"value"`
+	Doc      string      `json:"doc,omitempty"`
+	Position *Position   `json:"position,omitempty"`
+}
+
+// Relation holds what a relation adds to the concept of the
+// same name: its ends and its properties.
+type Relation struct {
+	Name       string       `json:"name"`
+	From       *RelationEnd `json:"from"`
+	To         *RelationEnd `json:"to"`
+	Inverse    string       `json:"inverse,omitempty"` // the inverse relation, if there is one
+	Symmetric  bool         `json:"symmetric,omitempty"`
+	Transitive bool         `json:"transitive,omitempty"`
+	Acyclic    bool         `json:"acyclic,omitempty"`
+	Position   *Position    `json:"position,omitempty"`
+}
+
+// RelationEnd is one of the ends of a relation.
+type RelationEnd struct {
+	Concept     *Type  `json:"concept"`
+	Cardinality string `json:"cardinality"` // "one" or "many"
+}
+
+// Write writes the schema to w as indented JSON.
+func Write(w io.Writer, schema *Schema) error {
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// Read reads a schema written by Write. Numbers are read as
+// json.Number, as Build gives them, so that integers and
+// floats stay apart. Schemas of another version are errors.
+func Read(r io.Reader) (*Schema, error) {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	schema := &Schema{}
+	if err := d.Decode(schema); err != nil {
+		return nil, err
+	}
+	if schema.Version != Version {
+		return nil, fmt.Errorf("unsupported IR version %d, expected %d", schema.Version, Version)
+	}
+	return schema, nil
+}
+
+// ReadFile reads the schema in the file at path.
+func ReadFile(path string) (*Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	schema, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,192 @@
+package ir
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/concept"
+	"github.com/riyanshkarani011235/meme/parser"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// resolves src as the file a.meme and returns its IR
+func build(src string) *Schema {
+	fset := token.NewFileSet()
+	f, err := parser.ParseSource(fset, "a.meme", src)
+	Expect(err).NotTo(HaveOccurred())
+
+	tree := concept.NewConceptTree()
+	err = tree.Resolve(fset, f)
+	Expect(err).NotTo(HaveOccurred(), "%v", err)
+	return Build(tree, fset)
+}
+
+const source = `package scrum
+
+// a box of anything
+concept Box<T> {
+	required item T
+}
+
+concept Issue extends Box<string> {
+	// how much work it is
+	@range(1, 13) @widget(slider) optional points integer = 3
+	optional status Status = Open
+	optional labels [string] = ["new"]
+}
+
+enum Status { Open, Closed = "done" }
+
+relation Blocks from many Issue to one Issue acyclic {}
+`
+
+var _ = Describe("Build", func() {
+	It("Should describe every declaration of the tree", func() {
+		schema := build(source)
+		Expect(schema.Version).To(Equal(Version))
+
+		Expect(schema.Concepts).To(HaveLen(4))
+		root, blocks, box, issue := schema.Concepts[0], schema.Concepts[1], schema.Concepts[2], schema.Concepts[3]
+		Expect(root).To(Equal(&Concept{Name: "Concept"}))
+		Expect(box.Name).To(Equal("scrum.Box"))
+		Expect(box.Package).To(Equal("scrum"))
+		Expect(box.Doc).To(Equal("a box of anything"))
+		Expect(box.TypeParams).To(Equal([]string{"T"}))
+		Expect(box.Position).To(Equal(&Position{File: "a.meme", Line: 4, Column: 9}))
+		Expect(box.Fields[0].Type).To(Equal(&Type{Kind: KindTypeParam, Name: "T"}))
+
+		Expect(issue.Extends).To(Equal(&Type{
+			Kind: KindConcept,
+			Name: "scrum.Box",
+			Args: []*Type{{Kind: KindPrimitive, Name: "string"}},
+		}))
+
+		// inherited fields come first, instantiated
+		Expect(issue.Fields).To(HaveLen(4))
+		item, points, status, labels := issue.Fields[0], issue.Fields[1], issue.Fields[2], issue.Fields[3]
+		Expect(item.Owner).To(Equal("scrum.Box"))
+		Expect(item.Required).To(BeTrue())
+		Expect(item.Type).To(Equal(&Type{Kind: KindPrimitive, Name: "string"}))
+		Expect(points.Owner).To(Equal("scrum.Issue"))
+		Expect(points.Doc).To(Equal("how much work it is"))
+		Expect(points.Default).To(Equal(json.Number("3")))
+		Expect(points.Annotations).To(Equal([]*Annotation{
+			{Name: "range", Args: []interface{}{json.Number("1"), json.Number("13")}},
+			{Name: "widget", Args: []interface{}{map[string]interface{}{"ident": "slider"}}},
+		}))
+		Expect(points.Position).To(Equal(&Position{File: "a.meme", Line: 10, Column: 41}))
+		Expect(status.Type).To(Equal(&Type{Kind: KindEnum, Name: "scrum.Status"}))
+		Expect(status.Default).To(Equal(map[string]interface{}{"enum": "scrum.Status", "member": "Open"}))
+		Expect(labels.Type).To(Equal(&Type{Kind: KindList, Elem: &Type{Kind: KindPrimitive, Name: "string"}}))
+		Expect(labels.Default).To(Equal([]interface{}{"new"}))
+
+		// relations are concepts too
+		Expect(blocks.Name).To(Equal("scrum.Blocks"))
+		Expect(blocks.Fields[0].Name).To(Equal("from"))
+
+		Expect(schema.Enums).To(HaveLen(1))
+		Expect(schema.Enums[0].Members).To(Equal([]*EnumMember{
+			{Name: "Open", Value: "Open", Position: &Position{File: "a.meme", Line: 15, Column: 15}},
+			{Name: "Closed", Value: "done", Position: &Position{File: "a.meme", Line: 15, Column: 21}},
+		}))
+
+		Expect(schema.Relations).To(Equal([]*Relation{{
+			Name:     "scrum.Blocks",
+			From:     &RelationEnd{Concept: &Type{Kind: KindConcept, Name: "scrum.Issue"}, Cardinality: "many"},
+			To:       &RelationEnd{Concept: &Type{Kind: KindConcept, Name: "scrum.Issue"}, Cardinality: "one"},
+			Acyclic:  true,
+			Position: &Position{File: "a.meme", Line: 17, Column: 10},
+		}}))
+	})
+
+	It("Should name files relative to the root and the builtin library as it is imported", func() {
+		fset := token.NewFileSet()
+		list, err := parser.ParseSource(fset, filepath.Join("lib", "list.meme"), `concept List {}`)
+		Expect(err).NotTo(HaveOccurred())
+		todo, err := parser.ParseSource(fset, filepath.Join("project", "schema", "todo", "todo.meme"),
+			`import "builtin/list.meme"
+			concept Todo extends List {}`)
+		Expect(err).NotTo(HaveOccurred())
+		item, err := parser.ParseSource(fset, filepath.Join("project", "schema", "item.meme"), `concept Item {}`)
+		Expect(err).NotTo(HaveOccurred())
+
+		tree := concept.NewConceptTree()
+		tree.SetBuiltin("lib")
+		Expect(tree.Resolve(fset, list, todo, item)).To(Succeed())
+
+		files := func(schema *Schema) []string {
+			names := make([]string, len(schema.Concepts))
+			for i, c := range schema.Concepts {
+				if c.Position != nil {
+					names[i] = c.Position.File
+				}
+			}
+			return names
+		}
+
+		// Concept, Item, List, Todo
+		Expect(files(Build(tree, fset))).To(Equal([]string{"", "item.meme", "builtin/list.meme", "todo/todo.meme"}))
+		Expect(files(Config{Root: "project"}.Build(tree, fset))).To(Equal(
+			[]string{"", "schema/item.meme", "builtin/list.meme", "schema/todo/todo.meme"}))
+	})
+
+	It("Should leave out positions without a file set", func() {
+		fset := token.NewFileSet()
+		f, err := parser.ParseSource(fset, "a.meme", `concept A { required b string }`)
+		Expect(err).NotTo(HaveOccurred())
+		tree := concept.NewConceptTree()
+		Expect(tree.Resolve(fset, f)).To(Succeed())
+
+		schema := Build(tree, nil)
+		Expect(schema.Concepts[0].Position).To(BeNil())
+		Expect(schema.Concepts[0].Fields[0].Position).To(BeNil())
+	})
+})
+
+var _ = Describe("Write and Read", func() {
+	It("Should read back the schema it writes", func() {
+		schema := build(source)
+
+		var buf bytes.Buffer
+		Expect(Write(&buf, schema)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("{\n  \"version\": 1,\n  \"concepts\": ["))
+
+		read, err := Read(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(Equal(schema))
+	})
+
+	It("Should keep whole floats floats", func() {
+		schema := build(`concept A { @weight(1.0, 2.5) optional a string }`)
+		Expect(schema.Concepts[0].Fields[0].Annotations[0].Args).To(Equal([]interface{}{json.Number("1.0"), json.Number("2.5")}))
+
+		var buf bytes.Buffer
+		Expect(Write(&buf, schema)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("1.0"))
+
+		read, err := Read(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(Equal(schema))
+	})
+
+	It("Should write empty lists rather than null", func() {
+		var buf bytes.Buffer
+		Expect(Write(&buf, build(``))).To(Succeed())
+		Expect(buf.String()).To(HaveSuffix("\"enums\": [],\n  \"relations\": []\n}\n"))
+	})
+
+	It("Should reject another version", func() {
+		_, err := Read(strings.NewReader(`{"version": 2, "concepts": []}`))
+		Expect(err).To(MatchError("unsupported IR version 2, expected 1"))
+	})
+
+	It("Should report malformed JSON", func() {
+		_, err := Read(strings.NewReader(`{"version": `))
+		Expect(err).To(HaveOccurred())
+	})
+})
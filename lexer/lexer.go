@@ -2,37 +2,82 @@ package lexer
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/riyanshkarani011235/meme/token"
 )
 
 const (
-	eof = '\r'
+	// returned by next at the end of the input. It is not a
+	// valid character, so it cannot appear in the input.
+	eof = -1
+
+	// the UTF-8 encoded byte order mark, skipped at the
+	// start of the input
+	bom = "\uFEFF"
 )
 
 type stateFn func(*Lexer) stateFn
 
 type Lexer struct {
 	input      string
-	lineNumber int              // the line number in the input string the lexer is at
+	fset       *token.FileSet   // the set positions of tokens refer to
+	file       *token.File      // the file the input was read from
 	startPos   int              // starting position of this item
 	currentPos int              // current position in the input
+	width      int              // width in bytes of the last character read
 	tokens     chan token.Token // the channel at which tokens are emitted
 	state      stateFn
+
+	// offset up to which line starts were added to file.
+	// Tokens are emitted in order, so the input only needs
+	// to be scanned for lines once, see addLines.
+	lineScanned int
+
+	// offsets of invalid UTF-8 sequences read inside the
+	// last comment, reported after it
+	invalid []int
 }
 
+// NewLexer returns a lexer for input that does not come
+// from a file. Its tokens refer to an unnamed file of a
+// new FileSet.
 func NewLexer(input string) (l *Lexer) {
+	return NewLexerForFile(token.NewFileSet(), "", "", input)
+}
+
+// NewLexerForFile adds a file to fset and returns a lexer
+// for its contents. The positions of the tokens it emits
+// are positions of the file in fset.
+func NewLexerForFile(fset *token.FileSet, name string, path string, input string) (l *Lexer) {
 	l = &Lexer{
 		input:      input,
-		lineNumber: 0,
+		fset:       fset,
+		file:       fset.AddFile(name, path, len(input)),
 		startPos:   0,
 		currentPos: 0,
 		tokens:     make(chan token.Token, 1),
 		state:      startState,
 	}
+
+	// columns are counted in characters, not in bytes
+	l.file.SetSource(input)
 	return
 }
 
+// FileSet returns the set the positions of tokens refer to.
+func (l *Lexer) FileSet() *token.FileSet {
+	return l.fset
+}
+
+// File returns the file the lexer reads.
+func (l *Lexer) File() *token.File {
+	return l.file
+}
+
 // Tokenizes the entire input and returns
 // a slice of token.Token instances. If using
 // the lexer in conjunction with a parser,
@@ -69,10 +114,9 @@ func (l *Lexer) NextToken() (t token.Token, ok bool) {
 
 	// generate the next state while at least
 	// one token is emitted, then stop. This should
-	// always work because if the previous state is
-	// not nil and the current state becomes nil,
-	// either an EOF token or a SynatxError token is
-	// emitted.
+	// always work because the state only becomes nil
+	// after the EOF token is emitted. Errors are
+	// emitted as TokenError and lexing carries on.
 	for len(l.tokens) == 0 {
 		l.state = l.state(l)
 	}
@@ -86,22 +130,67 @@ func (l *Lexer) emit(t token.Token) {
 	l.startPos = l.currentPos
 }
 
-// reads one character from the input and returns it
-func (l *Lexer) next() (character byte) {
+// returns a token of the given type starting at startPos
+func (l *Lexer) newToken(tokenType token.TokenType, literal string) token.Token {
+	l.addLines(l.currentPos)
+
+	return token.Token{
+		Type:    tokenType,
+		Literal: literal,
+		Pos:     l.file.Pos(l.offset(l.startPos)),
+	}
+}
+
+// clamps a position to the input, positions past the end
+// are the result of reading eof
+func (l *Lexer) offset(pos int) int {
+	if pos > len(l.input) {
+		return len(l.input)
+	}
+	return pos
+}
+
+// adds the start of every line before pos to the file
+func (l *Lexer) addLines(pos int) {
+	for pos = l.offset(pos); l.lineScanned < pos; l.lineScanned++ {
+		switch l.input[l.lineScanned] {
+		case '\n':
+			l.file.AddLine(l.lineScanned + 1)
+		case '\r':
+			// a lone '\r' ends a line too, "\r\n" ends a
+			// single line at the '\n'
+			if l.lineScanned+1 == len(l.input) || l.input[l.lineScanned+1] != '\n' {
+				l.file.AddLine(l.lineScanned + 1)
+			}
+		}
+	}
+}
+
+// reads one UTF-8 encoded character from the input and
+// returns it. A byte that is not part of a valid encoding
+// is returned as utf8.RuneError, see isInvalid.
+func (l *Lexer) next() (character rune) {
 	if l.currentPos >= len(l.input) {
 		character = eof
+		l.width = 1
 		l.currentPos += 1
 		return
 	}
 
-	character = l.input[l.currentPos]
-	l.currentPos += 1
+	character, l.width = utf8.DecodeRuneInString(l.input[l.currentPos:])
+	l.currentPos += l.width
 	return
 }
 
+// reports whether the last character read was an invalid
+// UTF-8 sequence rather than an encoded U+FFFD
+func (l *Lexer) isInvalid(character rune) bool {
+	return character == utf8.RuneError && l.width == 1
+}
+
 // peeks the next character without incrementing
 // currentPos
-func (l *Lexer) peek() (character byte) {
+func (l *Lexer) peek() (character rune) {
 	character = l.next()
 	l.backup()
 	return
@@ -112,9 +201,37 @@ func (l *Lexer) ignore() {
 	l.startPos = l.currentPos
 }
 
-// backs up in the input by one character
+// consumes the next character if it is in valid
+func (l *Lexer) accept(valid string) bool {
+	if strings.ContainsRune(valid, l.next()) {
+		return true
+	}
+	l.backup()
+	return false
+}
+
+// consumes a run of characters for which f is true and
+// returns their number
+func (l *Lexer) acceptRun(f func(rune) bool) (n int) {
+	for f(l.next()) {
+		n += 1
+	}
+	l.backup()
+	return
+}
+
+// records c if it is an invalid UTF-8 sequence, so that it
+// is reported once the comment it is part of is emitted
+func (l *Lexer) checkEncoding(c rune) {
+	if l.isInvalid(c) {
+		l.invalid = append(l.invalid, l.currentPos-l.width)
+	}
+}
+
+// backs up in the input by one character. Can only be
+// called once per call of next.
 func (l *Lexer) backup() {
-	l.currentPos -= 1
+	l.currentPos -= l.width
 
 	// @todo panic instead?
 	if l.startPos > l.currentPos {
@@ -126,25 +243,36 @@ func (l *Lexer) backup() {
 // helper functions
 // ----------------
 
-func isAlphabet(character byte) bool {
-	return ('a' <= character && character <= 'z') || ('A' <= character && character <= 'Z')
+// letters and digits are those of Unicode, as in Go
+// identifiers
+func isAlphabet(character rune) bool {
+	return unicode.IsLetter(character)
+}
+
+func isNumeric(character rune) bool {
+	return unicode.IsDigit(character)
 }
 
-func isNumeric(character byte) bool {
+// digits of number literals are ASCII only
+func isDecimal(character rune) bool {
 	return '0' <= character && character <= '9'
 }
 
-func isAlphaNumeric(character byte) bool {
+func isHexadecimal(character rune) bool {
+	return isDecimal(character) || ('a' <= character && character <= 'f') || ('A' <= character && character <= 'F')
+}
+
+func isAlphaNumeric(character rune) bool {
 	return isAlphabet(character) || isNumeric(character)
 }
 
-func isAlphaNumericOrUnderscore(character byte) bool {
+func isAlphaNumericOrUnderscore(character rune) bool {
 	return isAlphaNumeric(character) || character == '_'
 }
 
-func isWhiteSpace(character byte) bool {
+func isWhiteSpace(character rune) bool {
 	switch character {
-	case ' ', '\t', '\n':
+	case ' ', '\t', '\n', '\r':
 		return true
 	default:
 		return false
@@ -158,18 +286,18 @@ func isWhiteSpace(character byte) bool {
 func startState(l *Lexer) stateFn {
 	l.startPos = 0
 	l.currentPos = 0
+	if strings.HasPrefix(l.input, bom) {
+		l.currentPos = len(bom)
+		l.ignore()
+	}
 	return tokenizeText
 }
 
 func eatWhiteSpace(l *Lexer) stateFn {
 	switch l.next() {
-	case ' ', '\t':
-		// keep eating white space
-		return eatWhiteSpace
-	case '\n':
-		// increment the line number
-		l.lineNumber += 1
-		// continue eating white space
+	case ' ', '\t', '\n', '\r':
+		// keep eating white space, lines are counted when
+		// tokens are emitted
 		return eatWhiteSpace
 	default:
 		// no longer a white space, ignore the white space read
@@ -181,6 +309,10 @@ func eatWhiteSpace(l *Lexer) stateFn {
 }
 
 func tokenizeText(l *Lexer) stateFn {
+	if len(l.invalid) > 0 {
+		return reportInvalidEncoding
+	}
+
 	c := l.next()
 
 	// whitespace
@@ -190,11 +322,17 @@ func tokenizeText(l *Lexer) stateFn {
 	}
 
 	// keyword or identifier
-	if isAlphabet(c) {
+	if isAlphabet(c) || c == '_' {
 		l.backup()
 		return tokenizeKeywordOrIdentifier
 	}
 
+	// number literal
+	if isDecimal(c) {
+		l.backup()
+		return tokenizeNumber
+	}
+
 	switch c {
 	// parens or braces
 	case '{', '}', '(', ')', '[', ']', '<', '>':
@@ -207,10 +345,34 @@ func tokenizeText(l *Lexer) stateFn {
 		return tokenizeEndOfFile
 
 	// delimiters
-	case ',':
+	case ',', '=':
+		l.backup()
+		return tokenizeSpecialCharacters
+
+	// annotations
+	case '@':
+		l.backup()
+		return tokenizeSpecialCharacters
+
+	// qualified names
+	case '.':
 		l.backup()
 		return tokenizeSpecialCharacters
 
+	// negative number literal
+	case '-':
+		if isDecimal(l.peek()) {
+			l.backup()
+			return tokenizeNumber
+		}
+		return generateSyntaxError("unexpected character %q", c)
+
+	// string literals, the content starts after the quote
+	case '"':
+		return tokenizeString
+	case '`':
+		return tokenizeRawString
+
 	// comments
 	case '/':
 		nextCharacter := l.next()
@@ -222,64 +384,71 @@ func tokenizeText(l *Lexer) stateFn {
 			return tokenizeSingleLineComment
 		} else if nextCharacter == '*' {
 			return tokenizeMultiLineComment
-		} else {
-			return syntaxError
 		}
 
+		// read the next character again, as the start of
+		// the next token
+		l.backup()
+		return generateSyntaxError("lone '/'")
+
 	// anything else, must be a syntax error
 	default:
-		return syntaxError
+		if l.isInvalid(c) {
+			return generateSyntaxError("invalid UTF-8 encoding")
+		}
+		return generateSyntaxError("unexpected character %q", c)
 	}
 }
 
 func tokenizeSingleLineComment(l *Lexer) stateFn {
-	switch l.next() {
-	case '\n', eof:
+	switch c := l.next(); c {
+	case '\n', '\r', eof:
 		// end of comment
 		l.backup()
-		l.emit(token.Token{
-			Type:       token.TokenSingleLineComment,
-			Literal:    l.input[l.startPos:l.currentPos],
-			LineNumber: l.lineNumber,
-			// @todo FileInfo
-			ColumnNumberStart: l.startPos,
-			ColumnNumberEnd:   l.currentPos,
-		})
+		l.emit(l.newToken(token.TokenSingleLineComment, l.input[l.startPos:l.currentPos]))
 		return tokenizeText
 	default:
 		// continue reading comment
+		l.checkEncoding(c)
 		return tokenizeSingleLineComment
 	}
 }
 
 func tokenizeMultiLineComment(l *Lexer) stateFn {
-	switch l.next() {
+	switch c := l.next(); c {
 	case '*':
 		if l.peek() == '/' {
 			// end of comment
 			l.next()
-			l.emit(token.Token{
-				Type:       token.TokenMultiLineComment,
-				Literal:    l.input[l.startPos:l.currentPos],
-				LineNumber: l.lineNumber,
-				// @todo FileInfo
-				ColumnNumberStart: l.startPos,
-				ColumnNumberEnd:   l.currentPos,
-			})
-			fmt.Println("returning to tokenizeText")
+			l.emit(l.newToken(token.TokenMultiLineComment, l.input[l.startPos:l.currentPos]))
 			return tokenizeText
 		} else {
 			// continue reading comment
 			return tokenizeMultiLineComment
 		}
 	case eof:
-		// premature end of file, before end of multi line comment
-		return syntaxError
+		// premature end of file, before end of multi line
+		// comment. Report it at the end of the file, which
+		// is then read again as EOF.
+		l.backup()
+		l.addLines(l.startPos)
+		start := l.file.Position(l.file.Pos(l.startPos))
+		l.ignore()
+		return generateSyntaxError("unterminated block comment started at %d:%d", start.Line, start.Column)
 	default:
 		// continue reading the comment
+		l.checkEncoding(c)
 		return tokenizeMultiLineComment
 	}
+}
 
+// emits an error token for the first invalid UTF-8 sequence
+// recorded while reading a comment
+func reportInvalidEncoding(l *Lexer) stateFn {
+	offset := l.invalid[0]
+	l.invalid = l.invalid[1:]
+	l.emit(token.Token{Type: token.TokenError, Literal: "invalid UTF-8 encoding", Pos: l.file.Pos(offset)})
+	return tokenizeText
 }
 
 func tokenizeSpecialCharacters(l *Lexer) stateFn {
@@ -289,14 +458,7 @@ func tokenizeSpecialCharacters(l *Lexer) stateFn {
 		panic(fmt.Sprintf("unrecognized special character %v", c))
 	}
 
-	l.emit(token.Token{
-		Type:       tokenType,
-		Literal:    string(c),
-		LineNumber: l.lineNumber,
-		// @todo FileInfo
-		ColumnNumberStart: l.currentPos,
-		ColumnNumberEnd:   l.currentPos,
-	})
+	l.emit(l.newToken(tokenType, string(c)))
 
 	return tokenizeText
 }
@@ -314,39 +476,173 @@ func tokenizeKeywordOrIdentifier(l *Lexer) stateFn {
 	literal := l.input[l.startPos:l.currentPos]
 
 	// we now have a literal, build a token out of it
-	t := token.Token{
-		Literal:    literal,
-		LineNumber: l.lineNumber,
-		// @todo FileInfo
-		ColumnNumberStart: l.startPos,
-		ColumnNumberEnd:   l.currentPos,
+	tokenType, ok := token.TokenTypeLookupMap[literal]
+	if !ok {
+		// is an identifier, not a keyword
+		tokenType = token.TokenIdentifier
+	}
+
+	l.emit(l.newToken(tokenType, literal))
+	return tokenizeText
+}
+
+// 42, -1_000, 0x2A, 4.2, 1e-3 or 1.5E+10
+func tokenizeNumber(l *Lexer) stateFn {
+	tokenType := token.TokenIntegerLiteral
+	base, isDigit := "decimal", isDecimal
+	sign := 0
+	if l.accept("-") {
+		sign = 1
+	}
+	if l.accept("0") && l.accept("xX") {
+		base, isDigit = "hexadecimal", isHexadecimal
 	}
 
-	if tokenType, ok := token.TokenTypeLookupMap[literal]; ok {
-		// is a keyword
-		t.Type = tokenType
-	} else {
-		// is an identifier
-		t.Type = token.TokenIdentifier
+	digitOrSeparator := func(c rune) bool { return isDigit(c) || c == '_' }
+	if l.acceptRun(digitOrSeparator) == 0 && base == "hexadecimal" {
+		return generateSyntaxError("hexadecimal literal has no digits")
 	}
 
-	l.emit(t)
+	if base == "decimal" {
+		// fraction
+		if l.accept(".") {
+			tokenType = token.TokenFloatLiteral
+			if !isDecimal(l.peek()) {
+				return generateSyntaxErrorAt(l.currentPos, "missing digits after decimal point")
+			}
+			l.acceptRun(digitOrSeparator)
+		}
+
+		// exponent
+		if l.accept("eE") {
+			tokenType = token.TokenFloatLiteral
+			l.accept("+-")
+			if !isDecimal(l.peek()) {
+				return generateSyntaxErrorAt(l.currentPos, "exponent has no digits")
+			}
+			l.acceptRun(digitOrSeparator)
+		}
+	}
+
+	// a number must not run into an identifier, e.g. 12ab
+	if c := l.peek(); isAlphaNumericOrUnderscore(c) {
+		offset := l.currentPos
+		l.acceptRun(isAlphaNumericOrUnderscore)
+		return generateSyntaxErrorAt(offset, "invalid character %q in %s literal", c, base)
+	}
+
+	literal := l.input[l.startPos:l.currentPos]
+	if i := invalidSeparator(literal[sign:], isDigit); i >= 0 {
+		return generateSyntaxErrorAt(l.startPos+sign+i, "'_' must separate successive digits")
+	}
+
+	l.emit(l.newToken(tokenType, literal))
 	return tokenizeText
 }
 
+// returns the index of the first '_' in a number literal
+// that is not between two digits, or -1. The 'x' of a
+// hexadecimal prefix counts as a digit.
+func invalidSeparator(literal string, isDigit func(rune) bool) int {
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '_' {
+			continue
+		}
+
+		before := i > 0 && (isDigit(rune(literal[i-1])) || (i == 2 && (literal[1] == 'x' || literal[1] == 'X')))
+		after := i+1 < len(literal) && isDigit(rune(literal[i+1]))
+		if !before || !after {
+			return i
+		}
+	}
+	return -1
+}
+
+// "...", with the escape sequences of Go string literals
+func tokenizeString(l *Lexer) stateFn {
+	invalidEscape, escape := -1, ""
+	for {
+		switch c := l.next(); c {
+		case '"':
+			if invalidEscape >= 0 {
+				return generateSyntaxErrorAt(invalidEscape, "invalid escape sequence `%s`", escape)
+			}
+			l.emit(l.newToken(token.TokenStringLiteral, l.input[l.startPos:l.currentPos]))
+			return tokenizeText
+
+		case '\\':
+			offset := l.currentPos - 1
+			if !l.acceptEscape() && invalidEscape < 0 {
+				invalidEscape, escape = offset, l.input[offset:l.currentPos]
+			}
+
+		case '\n', '\r', eof:
+			// the line break is read again as white space
+			l.backup()
+			return generateSyntaxError("string literal not terminated")
+
+		default:
+			l.checkEncoding(c)
+		}
+	}
+}
+
+// consumes the rest of an escape sequence after the '\'
+// and reports whether it is valid
+func (l *Lexer) acceptEscape() bool {
+	digits := 0
+	switch c := l.next(); c {
+	case 'a', 'b', 'f', 'n', 'r', 't', 'v', '\\', '"':
+		return true
+	case 'x':
+		digits = 2
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	case '\n', '\r', eof:
+		// not part of the escape, the string is not terminated
+		l.backup()
+		return true
+	default:
+		return false
+	}
+
+	start := l.currentPos
+	for i := 0; i < digits; i++ {
+		if !isHexadecimal(l.peek()) {
+			return false
+		}
+		l.next()
+	}
+
+	value, err := strconv.ParseUint(l.input[start:l.currentPos], 16, 32)
+	return err == nil && (digits == 2 || utf8.ValidRune(rune(value)))
+}
+
+// `...`, without escape sequences and possibly spanning
+// several lines
+func tokenizeRawString(l *Lexer) stateFn {
+	for {
+		switch c := l.next(); c {
+		case '`':
+			l.emit(l.newToken(token.TokenStringLiteral, l.input[l.startPos:l.currentPos]))
+			return tokenizeText
+		case eof:
+			l.backup()
+			return generateSyntaxError("raw string literal not terminated")
+		default:
+			l.checkEncoding(c)
+		}
+	}
+}
+
 func tokenizeEndOfFile(l *Lexer) stateFn {
 	c := l.next()
 
 	switch c {
 	case eof:
-		l.emit(token.Token{
-			Type:       token.TokenEOF,
-			Literal:    "EOF",
-			LineNumber: l.lineNumber,
-			// @todo FileInfo
-			ColumnNumberStart: l.startPos,
-			ColumnNumberEnd:   l.currentPos,
-		})
+		l.emit(l.newToken(token.TokenEOF, "EOF"))
 		return nil
 	default:
 		// should be an EOF, but is not. Panic
@@ -354,28 +650,23 @@ func tokenizeEndOfFile(l *Lexer) stateFn {
 	}
 }
 
-func generateSyntaxError(errorString string) func(*Lexer) stateFn {
-	return func(l *Lexer) stateFn {
-		l.emit(token.Token{
-			Type:       token.TokenError,
-			Literal:    errorString,
-			LineNumber: l.lineNumber,
-			// @todo FileInfo
-			ColumnNumberStart: l.startPos,
-			ColumnNumberEnd:   l.currentPos,
-		})
-		return nil
-	}
+// returns a state that emits an error token at the start of
+// the pending input, skips over the pending input and
+// carries on lexing after it
+func generateSyntaxError(format string, args ...interface{}) stateFn {
+	return generateSyntaxErrorAt(-1, format, args...)
 }
 
-func syntaxError(l *Lexer) stateFn {
-	l.emit(token.Token{
-		Type:       token.TokenError,
-		Literal:    "Syntax Error",
-		LineNumber: l.lineNumber,
-		// @todo FileInfo
-		ColumnNumberStart: l.startPos,
-		ColumnNumberEnd:   l.currentPos,
-	})
-	return nil
+// like generateSyntaxError, but the error token is at the
+// given offset of the pending input if it is not negative
+func generateSyntaxErrorAt(offset int, format string, args ...interface{}) stateFn {
+	errorString := fmt.Sprintf(format, args...)
+	return func(l *Lexer) stateFn {
+		t := l.newToken(token.TokenError, errorString)
+		if offset >= 0 {
+			t.Pos = l.file.Pos(offset)
+		}
+		l.emit(t)
+		return tokenizeText
+	}
 }
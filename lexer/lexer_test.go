@@ -1,6 +1,9 @@
 package lexer
 
 import (
+	"fmt"
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	// "github.com/riyanshkarani011235/meme/lexer"
@@ -12,6 +15,17 @@ type testStruct struct {
 	expectedLiteral string
 }
 
+// lexes input to the end and returns "type(literal)@line:column"
+// for every token
+func lex(input string) []string {
+	l := NewLexer(input)
+	tokens := make([]string, 0)
+	for t, ok := l.NextToken(); ok; t, ok = l.NextToken() {
+		tokens = append(tokens, fmt.Sprintf("%v(%s)@%s", t.Type, t.Literal, l.FileSet().Position(t.Pos)))
+	}
+	return tokens
+}
+
 var _ = Describe("tokenize and NextToken", func() {
 	Context("Testing Braces and Parens", func() {
 		testString := "(){}[]<>"
@@ -56,6 +70,32 @@ var _ = Describe("tokenize and NextToken", func() {
 		})
 	})
 
+	Context("Testing enum", func() {
+		testString := "enum Status { Todo, Done = 2 }"
+		testOutput := []*testStruct{
+			&testStruct{token.TokenEnum, "enum"},
+			&testStruct{token.TokenIdentifier, "Status"},
+			&testStruct{token.TokenLeftBrace, "{"},
+			&testStruct{token.TokenIdentifier, "Todo"},
+			&testStruct{token.TokenComma, ","},
+			&testStruct{token.TokenIdentifier, "Done"},
+			&testStruct{token.TokenAssign, "="},
+			&testStruct{token.TokenIntegerLiteral, "2"},
+			&testStruct{token.TokenRightBrace, "}"},
+			&testStruct{token.TokenEOF, "EOF"},
+		}
+
+		It("Tokenize should generate correct Tokens", func() {
+			l := NewLexer(testString)
+			testTokenize(l, testOutput)
+		})
+
+		It("NextToken Should generate correct tokens", func() {
+			l := NewLexer(testString)
+			testNextToken(l, testOutput)
+		})
+	})
+
 	Context("Testing Keywords and Identifiers", func() {
 		testString := `concept Hello<T> extends World {
 				required foo [oneof(Concept, Relation)]
@@ -119,6 +159,289 @@ var _ = Describe("tokenize and NextToken", func() {
 	})
 })
 
+var _ = Describe("error recovery", func() {
+	It("Should report an unexpected character and keep going", func() {
+		Expect(lex("concept #A {}")).To(Equal([]string{
+			"CONCEPT(concept)@1:1",
+			"ERROR(unexpected character '#')@1:9",
+			"IDENTIFIER(A)@1:10",
+			"LEFT_BRACE({)@1:12",
+			"RIGHT_BRACE(})@1:13",
+			"EOF(EOF)@1:14",
+		}))
+	})
+
+	It("Should report a lone '/' and lex the character after it", func() {
+		Expect(lex("a /b")).To(Equal([]string{
+			"IDENTIFIER(a)@1:1",
+			"ERROR(lone '/')@1:3",
+			"IDENTIFIER(b)@1:4",
+			"EOF(EOF)@1:5",
+		}))
+	})
+
+	It("Should report an unterminated block comment at the end of the file", func() {
+		Expect(lex("a\n\n    /* b\n c")).To(Equal([]string{
+			"IDENTIFIER(a)@1:1",
+			"ERROR(unterminated block comment started at 3:5)@4:3",
+			"EOF(EOF)@4:3",
+		}))
+	})
+
+	It("Should report every error in the input", func() {
+		errors := 0
+		for _, t := range lex("! concept # A $ {} %") {
+			if strings.HasPrefix(t, "ERROR(") {
+				errors += 1
+			}
+		}
+		Expect(errors).To(Equal(4))
+	})
+})
+
+var _ = Describe("line endings", func() {
+	It("Should lex CRLF files to the end", func() {
+		Expect(lex("concept A {\r\n\trequired b B // b\r\n}\r\n")).To(Equal([]string{
+			"CONCEPT(concept)@1:1", "IDENTIFIER(A)@1:9", "LEFT_BRACE({)@1:11",
+			"REQUIRED(required)@2:2", "IDENTIFIER(b)@2:11", "IDENTIFIER(B)@2:13", "SINGLE_LINE_COMMENT(// b)@2:15",
+			"RIGHT_BRACE(})@3:1",
+			"EOF(EOF)@4:1",
+		}))
+	})
+
+	It("Should count lone carriage returns as line endings", func() {
+		Expect(lex("a\rb\r\rc /* d\r\ne\r */ f")).To(Equal([]string{
+			"IDENTIFIER(a)@1:1", "IDENTIFIER(b)@2:1", "IDENTIFIER(c)@4:1",
+			"MULTI_LINE_COMMENT(/* d\r\ne\r */)@4:3", "IDENTIFIER(f)@6:5", "EOF(EOF)@6:6",
+		}))
+	})
+
+	It("Should skip a byte order mark at the start of the input", func() {
+		Expect(lex("\uFEFFconcept A {}")).To(Equal([]string{
+			"CONCEPT(concept)@1:1", "IDENTIFIER(A)@1:9", "LEFT_BRACE({)@1:11", "RIGHT_BRACE(})@1:12", "EOF(EOF)@1:13",
+		}))
+	})
+})
+
+var _ = Describe("unicode", func() {
+	It("Should accept letters and digits of any script in identifiers", func() {
+		Expect(lex("concept Größe { required 名前 string_٣ }")).To(Equal([]string{
+			"CONCEPT(concept)@1:1",
+			"IDENTIFIER(Größe)@1:9",
+			"LEFT_BRACE({)@1:15",
+			"REQUIRED(required)@1:17",
+			"IDENTIFIER(名前)@1:26",
+			"IDENTIFIER(string_٣)@1:29",
+			"RIGHT_BRACE(})@1:38",
+			"EOF(EOF)@1:39",
+		}))
+	})
+
+	It("Should accept identifiers starting with an underscore", func() {
+		Expect(lex("_a1")).To(Equal([]string{"IDENTIFIER(_a1)@1:1", "EOF(EOF)@1:4"}))
+	})
+
+	It("Should count columns in characters after multi-byte comments", func() {
+		Expect(lex("/* ünïcödé */ a // 🙂\n  b")).To(Equal([]string{
+			"MULTI_LINE_COMMENT(/* ünïcödé */)@1:1",
+			"IDENTIFIER(a)@1:15",
+			"SINGLE_LINE_COMMENT(// 🙂)@1:17",
+			"IDENTIFIER(b)@2:3",
+			"EOF(EOF)@2:4",
+		}))
+	})
+
+	It("Should report invalid UTF-8 and unexpected characters separately", func() {
+		Expect(lex("a \xff b € /* \xfe */ c")).To(Equal([]string{
+			"IDENTIFIER(a)@1:1",
+			"ERROR(invalid UTF-8 encoding)@1:3",
+			"IDENTIFIER(b)@1:5",
+			"ERROR(unexpected character '€')@1:7",
+			"MULTI_LINE_COMMENT(/* \xfe */)@1:9",
+			"ERROR(invalid UTF-8 encoding)@1:12",
+			"IDENTIFIER(c)@1:17",
+			"EOF(EOF)@1:18",
+		}))
+	})
+})
+
+var _ = Describe("literals", func() {
+	It("Should lex integer and float literals", func() {
+		Expect(lex("0 42 1_000 0x2a 0X_FF_FF 4.2 1e3 1.5E-3 2_0.0_1e+1_0")).To(Equal([]string{
+			"INTEGER_LITERAL(0)@1:1",
+			"INTEGER_LITERAL(42)@1:3",
+			"INTEGER_LITERAL(1_000)@1:6",
+			"INTEGER_LITERAL(0x2a)@1:12",
+			"INTEGER_LITERAL(0X_FF_FF)@1:17",
+			"FLOAT_LITERAL(4.2)@1:26",
+			"FLOAT_LITERAL(1e3)@1:30",
+			"FLOAT_LITERAL(1.5E-3)@1:34",
+			"FLOAT_LITERAL(2_0.0_1e+1_0)@1:41",
+			"EOF(EOF)@1:53",
+		}))
+	})
+
+	It("Should report malformed numbers and carry on", func() {
+		Expect(lex("0x 1__0 1_ 3. 1e+ 12ab 0x1g 7")).To(Equal([]string{
+			"ERROR(hexadecimal literal has no digits)@1:1",
+			"ERROR('_' must separate successive digits)@1:5",
+			"ERROR('_' must separate successive digits)@1:10",
+			"ERROR(missing digits after decimal point)@1:14",
+			"ERROR(exponent has no digits)@1:18",
+			"ERROR(invalid character 'a' in decimal literal)@1:21",
+			"ERROR(invalid character 'g' in hexadecimal literal)@1:27",
+			"INTEGER_LITERAL(7)@1:29",
+			"EOF(EOF)@1:30",
+		}))
+	})
+
+	It("Should lex string literals with escape sequences", func() {
+		Expect(lex(`"" "a\tb" "\"q\" \\ \x41\u00e9\U0001F642" "ünï"`)).To(Equal([]string{
+			`STRING_LITERAL("")@1:1`,
+			`STRING_LITERAL("a\tb")@1:4`,
+			`STRING_LITERAL("\"q\" \\ \x41\u00e9\U0001F642")@1:11`,
+			`STRING_LITERAL("ünï")@1:43`,
+			"EOF(EOF)@1:48",
+		}))
+	})
+
+	It("Should lex raw strings over several lines", func() {
+		Expect(lex("`a\n\\n \"b\"` c")).To(Equal([]string{
+			"STRING_LITERAL(`a\n\\n \"b\"`)@1:1",
+			"IDENTIFIER(c)@2:9",
+			"EOF(EOF)@2:10",
+		}))
+	})
+
+	It("Should report malformed strings and carry on", func() {
+		Expect(lex("\"a\\qb\\x\" \"\\uD800\" \"open\nb `raw")).To(Equal([]string{
+			"ERROR(invalid escape sequence `\\q`)@1:3",
+			"ERROR(invalid escape sequence `\\uD800`)@1:11",
+			"ERROR(string literal not terminated)@1:19",
+			"IDENTIFIER(b)@2:1",
+			"ERROR(raw string literal not terminated)@2:3",
+			"EOF(EOF)@2:7",
+		}))
+	})
+
+	It("Should lex negative numbers and the assignment", func() {
+		Expect(lex("= -1 -0x_F -2.5e-1 - 1")).To(Equal([]string{
+			"ASSIGN(=)@1:1",
+			"INTEGER_LITERAL(-1)@1:3",
+			"INTEGER_LITERAL(-0x_F)@1:6",
+			"FLOAT_LITERAL(-2.5e-1)@1:12",
+			"ERROR(unexpected character '-')@1:20",
+			"INTEGER_LITERAL(1)@1:22",
+			"EOF(EOF)@1:23",
+		}))
+	})
+
+	It("Should lex true and false as keywords", func() {
+		Expect(lex("true false truth")).To(Equal([]string{
+			"TRUE(true)@1:1",
+			"FALSE(false)@1:6",
+			"IDENTIFIER(truth)@1:12",
+			"EOF(EOF)@1:17",
+		}))
+	})
+
+	It("Should lex annotations", func() {
+		Expect(lex(`@min(0) @deprecated @x#y`)).To(Equal([]string{
+			"AT(@)@1:1",
+			"IDENTIFIER(min)@1:2",
+			"LEFT_PAREN(()@1:5",
+			"INTEGER_LITERAL(0)@1:6",
+			"RIGHT_PAREN())@1:7",
+			"AT(@)@1:9",
+			"IDENTIFIER(deprecated)@1:10",
+			"AT(@)@1:21",
+			"IDENTIFIER(x)@1:22",
+			"ERROR(unexpected character '#')@1:23",
+			"IDENTIFIER(y)@1:24",
+			"EOF(EOF)@1:25",
+		}))
+	})
+
+	It("Should lex package clauses and qualified names", func() {
+		Expect(lex(`package scrum import s "s" s.Issue 1.5`)).To(Equal([]string{
+			"PACKAGE(package)@1:1",
+			"IDENTIFIER(scrum)@1:9",
+			"IMPORT(import)@1:15",
+			"IDENTIFIER(s)@1:22",
+			`STRING_LITERAL("s")@1:24`,
+			"IDENTIFIER(s)@1:28",
+			"DOT(.)@1:29",
+			"IDENTIFIER(Issue)@1:30",
+			"FLOAT_LITERAL(1.5)@1:36",
+			"EOF(EOF)@1:39",
+		}))
+	})
+})
+
+var _ = Describe("token positions", func() {
+	type position struct {
+		literal string
+		line    int
+		column  int
+		offset  int
+	}
+
+	testString := "concept A {\n\trequired b [B]\n}\n// done\n/* multi\nline */ "
+	expected := []position{
+		{"concept", 1, 1, 0},
+		{"A", 1, 9, 8},
+		{"{", 1, 11, 10},
+		{"required", 2, 2, 13},
+		{"b", 2, 11, 22},
+		{"[", 2, 13, 24},
+		{"B", 2, 14, 25},
+		{"]", 2, 15, 26},
+		{"}", 3, 1, 28},
+		{"// done", 4, 1, 30},
+		{"/* multi\nline */", 5, 1, 38},
+		{"EOF", 6, 9, 55},
+	}
+
+	It("Should give every token a position in the FileSet", func() {
+		l := NewLexer(testString)
+		i := 0
+		for t, ok := l.NextToken(); ok; t, ok = l.NextToken() {
+			Expect(i).To(BeNumerically("<", len(expected)))
+			e := expected[i]
+			if t.Type != token.TokenEOF {
+				Expect(t.Literal).To(Equal(e.literal))
+				Expect(l.File().Offset(t.End())).To(Equal(e.offset+len(e.literal)), e.literal)
+			}
+
+			pos := l.FileSet().Position(t.Pos)
+			Expect(pos.Filename).To(BeEmpty())
+			Expect(pos.Line).To(Equal(e.line), e.literal)
+			Expect(pos.Column).To(Equal(e.column), e.literal)
+			Expect(pos.Offset).To(Equal(e.offset), e.literal)
+			i += 1
+		}
+		Expect(i).To(Equal(len(expected)))
+	})
+
+	It("Should add files to the given FileSet", func() {
+		fset := token.NewFileSet()
+		a := NewLexerForFile(fset, "a.meme", "/schemas/a.meme", "concept A {}")
+		b := NewLexerForFile(fset, "b.meme", "/schemas/b.meme", "\nconcept B {}")
+		Expect(fset.Files()).To(Equal([]*token.File{a.File(), b.File()}))
+		Expect(b.File().Path()).To(Equal("/schemas/b.meme"))
+
+		for _, l := range []*Lexer{a, b} {
+			for t, ok := l.NextToken(); ok; t, ok = l.NextToken() {
+				Expect(fset.File(t.Pos)).To(Equal(l.File()))
+			}
+		}
+
+		t, _ := NewLexerForFile(fset, "c.meme", "", "concept C {}").NextToken()
+		Expect(fset.Position(t.Pos).String()).To(Equal("c.meme:1:1"))
+		Expect(fset.Position(b.File().Pos(1)).String()).To(Equal("b.meme:2:1"))
+	})
+})
+
 func testTokensList(tokens []token.Token, testInput []*testStruct) {
 	// verify each token
 	for i, expectedOutput := range testInput {
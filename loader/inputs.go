@@ -0,0 +1,150 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Filter selects files by include and exclude patterns. The
+// patterns use the syntax of filepath.Match, and a pattern
+// matches a file if it matches its path or a part of it made
+// of whole elements, e.g. "gen" and "gen/*.meme" both match
+// the file "schema/gen/issue.meme".
+type Filter struct {
+	Include []string // if not empty, only files matching one of these are kept
+	Exclude []string // files matching one of these are dropped
+}
+
+// Match reports whether the filter keeps the file at path.
+func (f Filter) Match(path string) bool {
+	if matchAny(f.Exclude, path) {
+		return false
+	}
+	return len(f.Include) == 0 || matchAny(f.Include, path)
+}
+
+// Inputs returns the .meme files named by patterns, in the
+// order of the patterns and without duplicates. A pattern is
+// one of
+//
+//	issue.meme       a file
+//	schema           every .meme file in the tree below a directory
+//	schema/...       the same, as in ./... for the current directory
+//	schema/*.meme    a glob pattern, whose directories are walked too
+//
+// Hidden directories are skipped when walking, and so are
+// directories excluded by filter. Files named explicitly are
+// kept even if the filter drops them. A pattern that names
+// no file is an error.
+func Inputs(patterns []string, filter Filter) ([]string, error) {
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		found, err := inputs(pattern, filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no .meme files match %s", pattern)
+		}
+
+		for _, name := range found {
+			if !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
+// returns the files named by a single pattern
+func inputs(pattern string, filter Filter) ([]string, error) {
+	if pattern == "..." || strings.HasSuffix(pattern, "/...") {
+		root := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+		if root == "" {
+			root = "."
+		}
+		return walk(root, filter)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return walk(pattern, filter)
+		}
+		return []string{filepath.Clean(pattern)}, nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %s", pattern, err)
+	}
+
+	files := make([]string, 0)
+	for _, name := range matches {
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case info.IsDir():
+			found, err := walk(name, filter)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		case strings.HasSuffix(name, ".meme") && filter.Match(name):
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// returns the .meme files kept by filter in the tree below
+// root, sorted
+func walk(root string, filter Filter) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case info.IsDir() && name != root && (strings.HasPrefix(info.Name(), ".") || matchAny(filter.Exclude, name)):
+			return filepath.SkipDir
+		case !info.IsDir() && strings.HasSuffix(name, ".meme") && filter.Match(name):
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// reports whether one of patterns matches path or a part of
+// it made of whole elements
+func matchAny(patterns []string, path string) bool {
+	elems := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(filepath.Clean(pattern))
+		for i := range elems {
+			for j := i + 1; j <= len(elems); j++ {
+				if ok, _ := filepath.Match(pattern, strings.Join(elems[i:j], "/")); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
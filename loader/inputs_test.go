@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inputs", func() {
+	var dir string
+
+	// returns the paths of the files named by patterns,
+	// relative to dir
+	inputs := func(filter Filter, patterns ...string) []string {
+		for i, pattern := range patterns {
+			patterns[i] = filepath.Join(dir, pattern)
+		}
+		files, err := Inputs(patterns, filter)
+		Expect(err).NotTo(HaveOccurred())
+
+		list := make([]string, len(files))
+		for i, f := range files {
+			rel, err := filepath.Rel(dir, f)
+			Expect(err).NotTo(HaveOccurred())
+			list[i] = filepath.ToSlash(rel)
+		}
+		return list
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "meme-inputs")
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFiles(dir, map[string]string{
+			"board.meme":              "",
+			"notes.txt":               "",
+			"scrum/issue.meme":        "",
+			"scrum/epic.meme":         "",
+			"scrum/gen/issue.meme":    "",
+			"scrum/.cache/issue.meme": "",
+		})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("Should walk directories and trees recursively", func() {
+		all := []string{"board.meme", "scrum/epic.meme", "scrum/gen/issue.meme", "scrum/issue.meme"}
+		Expect(inputs(Filter{}, ".")).To(Equal(all))
+		Expect(inputs(Filter{}, "...")).To(Equal(all))
+		Expect(inputs(Filter{}, "scrum/...")).To(Equal(all[1:]))
+	})
+
+	It("Should expand glob patterns and drop duplicates", func() {
+		Expect(inputs(Filter{}, "scrum/*.meme", "scrum/issue.meme", "*")).To(Equal([]string{
+			"scrum/epic.meme", "scrum/issue.meme", "board.meme", "scrum/gen/issue.meme",
+		}))
+	})
+
+	It("Should filter files by include and exclude patterns", func() {
+		Expect(inputs(Filter{Exclude: []string{"gen"}}, ".")).To(Equal([]string{"board.meme", "scrum/epic.meme", "scrum/issue.meme"}))
+		Expect(inputs(Filter{Include: []string{"issue.meme"}}, ".")).To(Equal([]string{"scrum/gen/issue.meme", "scrum/issue.meme"}))
+		Expect(inputs(Filter{Include: []string{"scrum/*"}, Exclude: []string{"epic.*"}}, ".")).To(Equal([]string{"scrum/gen/issue.meme", "scrum/issue.meme"}))
+
+		// files named explicitly are kept
+		Expect(inputs(Filter{Exclude: []string{"*.meme"}}, "board.meme")).To(Equal([]string{"board.meme"}))
+	})
+
+	It("Should report patterns that name no file", func() {
+		_, err := Inputs([]string{filepath.Join(dir, "*.schema")}, Filter{})
+		Expect(err).To(MatchError("no .meme files match " + filepath.Join(dir, "*.schema")))
+
+		_, err = Inputs([]string{filepath.Join(dir, "scrum/...")}, Filter{Exclude: []string{"scrum"}})
+		Expect(err).To(HaveOccurred())
+
+		_, err = Inputs([]string{filepath.Join(dir, "missing.meme")}, Filter{})
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,320 @@
+// Package loader reads meme files from disk along with every
+// file they import, and orders them so that each file comes
+// after the files it imports.
+package loader
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/parser"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// File is a file read by the loader.
+type File struct {
+	Path    string // the cleaned path of the file, also the name of AST
+	AST     *ast.File
+	Imports []*Import
+}
+
+// Import is an import declaration of a file and the files it
+// names: a single file, or every .meme file directly inside
+// an imported directory.
+type Import struct {
+	Decl  *ast.ImportDecl
+	Path  string // the cleaned path that is imported
+	Files []*File
+}
+
+// Program is a set of files that contains every file
+// imported by one of its files.
+type Program struct {
+	Fset *token.FileSet
+
+	// Files holds the files in dependency order: a file comes
+	// after the files it imports, unless they import it back
+	Files []*File
+
+	// Sources maps the path of every file to its contents
+	Sources diag.Sources
+}
+
+// File returns the file of the program with the given path,
+// or nil if there is no such file.
+func (prog *Program) File(path string) *File {
+	path = filepath.Clean(path)
+	for _, f := range prog.Files {
+		if f.Path == path {
+			return f
+		}
+	}
+	return nil
+}
+
+// ASTs returns the syntax trees of the files of the program,
+// in dependency order.
+func (prog *Program) ASTs() []*ast.File {
+	asts := make([]*ast.File, len(prog.Files))
+	for i, f := range prog.Files {
+		asts[i] = f.AST
+	}
+	return asts
+}
+
+// BuiltinPrefix starts the paths of imports of the builtin
+// library, as in "builtin/time.meme".
+const BuiltinPrefix = "builtin/"
+
+// Target returns the cleaned path imported by d, if d is
+// declared in the file at path file and the builtin library
+// is in the directory builtin. Without a builtin directory,
+// imports of the builtin library are relative like the
+// others; Load reports them instead of reading them.
+func Target(d *ast.ImportDecl, file string, builtin string) string {
+	value := d.Value()
+	if builtin != "" && strings.HasPrefix(value, BuiltinPrefix) {
+		return filepath.Join(builtin, filepath.FromSlash(strings.TrimPrefix(value, BuiltinPrefix)))
+	}
+
+	path := filepath.FromSlash(value)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(file), path)
+	}
+	return filepath.Clean(path)
+}
+
+// Config holds the options of the loader.
+type Config struct {
+	// Builtin is the directory of the builtin library, which
+	// imports starting with builtin/ are relative to. If it is
+	// empty, these imports are reported.
+	Builtin string
+}
+
+// Load reads the files at paths and, transitively, the files
+// they import, with the default options. See Config.Load.
+func Load(fset *token.FileSet, paths ...string) (*Program, error) {
+	return Config{}.Load(fset, paths...)
+}
+
+// Load reads the files at paths and, transitively, the files
+// they import. A path may name a directory, which stands for
+// every .meme file directly inside it. Imports are relative
+// to the directory of the importing file, or to the builtin
+// library; see Target.
+//
+// Files that cannot be read, imports of the builtin library
+// when it is not configured, syntax errors and import cycles
+// are all reported: the returned error is then a diag.List.
+// The program holds every file that could be read, so that
+// the diagnostics can be rendered with their sources.
+func (c Config) Load(fset *token.FileSet, paths ...string) (*Program, error) {
+	l := &loader{
+		prog:    &Program{Fset: fset, Sources: make(diag.Sources)},
+		files:   make(map[string]*File),
+		builtin: c.Builtin,
+	}
+
+	roots := make([]*File, 0, len(paths))
+	for _, path := range paths {
+		names, err := expand(filepath.Clean(path))
+		if err != nil {
+			l.diagnostics.Add(diag.Errorf(nil, diag.Span{}, diag.CodeImportNotFound, "cannot load %s: %s", path, reason(err)))
+			continue
+		}
+
+		for _, name := range names {
+			if f := l.load(name); f != nil {
+				roots = append(roots, f)
+			}
+		}
+	}
+
+	l.sort(roots)
+
+	l.diagnostics.Sort()
+	return l.prog, l.diagnostics.Err()
+}
+
+type loader struct {
+	prog        *Program
+	files       map[string]*File // by absolute path
+	builtin     string           // the directory of the builtin library
+	diagnostics diag.List
+
+	// the state of the files while sorting them, and the
+	// imports followed to reach the file being visited
+	state map[*File]int
+	stack []*Import
+	from  []*File
+}
+
+// the states of a file while sorting
+const (
+	unvisited = iota
+	visiting
+	visited
+)
+
+// reads and parses the file at path, then the files it
+// imports. Returns nil if the file cannot be read. A file
+// named by several paths, e.g. a relative and an absolute
+// one, is read once and keeps the first of them.
+func (l *loader) load(path string) *File {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+	if f, ok := l.files[key]; ok {
+		return f
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		l.diagnostics.Add(diag.Errorf(nil, diag.Span{}, diag.CodeImportNotFound, "cannot load %s: %s", path, reason(err)))
+		return nil
+	}
+
+	src := string(data)
+	l.prog.Sources[path] = src
+
+	tree, err := parser.ParseSource(l.prog.Fset, path, src)
+	if err != nil {
+		l.diagnostics = append(l.diagnostics, err.(diag.List)...)
+	}
+
+	f := &File{Path: path, AST: tree}
+	l.files[key] = f
+
+	for _, d := range tree.Imports {
+		imp := &Import{Decl: d, Path: Target(d, path, l.builtin)}
+		f.Imports = append(f.Imports, imp)
+
+		if l.builtin == "" && strings.HasPrefix(d.Value(), BuiltinPrefix) {
+			l.diagnostics.Add(diag.Errorf(l.prog.Fset, diag.SpanOf(d.Path), diag.CodeImportNotFound,
+				"cannot import %s: the builtin library was not found", d.Path.Value).
+				WithNote("give its directory with --builtin or MEME_BUILTIN"))
+			continue
+		}
+
+		names, err := expand(imp.Path)
+		if err != nil {
+			l.diagnostics.Add(diag.Errorf(l.prog.Fset, diag.SpanOf(d.Path), diag.CodeImportNotFound,
+				"cannot import %s: %s: %s", d.Path.Value, imp.Path, reason(err)))
+			continue
+		}
+
+		for _, name := range names {
+			if imported := l.load(name); imported != nil {
+				imp.Files = append(imp.Files, imported)
+			}
+		}
+	}
+
+	return f
+}
+
+// sorts the files reachable from roots in dependency order
+// into the program, reporting every import cycle
+func (l *loader) sort(roots []*File) {
+	l.state = make(map[*File]int)
+	for _, f := range roots {
+		l.visit(f)
+	}
+}
+
+// adds the files imported by f, then f, to the program
+func (l *loader) visit(f *File) {
+	switch l.state[f] {
+	case visited:
+		return
+	case visiting:
+		l.cycle(f)
+		return
+	}
+
+	l.state[f] = visiting
+	l.from = append(l.from, f)
+	for _, imp := range f.Imports {
+		l.stack = append(l.stack, imp)
+		for _, imported := range imp.Files {
+			l.visit(imported)
+		}
+		l.stack = l.stack[:len(l.stack)-1]
+	}
+	l.from = l.from[:len(l.from)-1]
+	l.state[f] = visited
+
+	l.prog.Files = append(l.prog.Files, f)
+}
+
+// reports the cycle closed by the last import on the stack,
+// which leads back to f
+func (l *loader) cycle(f *File) {
+	start := 0
+	for i, from := range l.from {
+		if from == f {
+			start = i
+		}
+	}
+
+	names := make([]string, 0, len(l.from)-start+1)
+	for _, from := range l.from[start:] {
+		names = append(names, from.Path)
+	}
+	names = append(names, f.Path)
+
+	last := l.stack[len(l.stack)-1]
+	d := diag.Errorf(l.prog.Fset, diag.SpanOf(last.Decl.Path), diag.CodeImportCycle,
+		"import cycle: %s", strings.Join(names, " -> "))
+	for i := start; i < len(l.stack)-1; i++ {
+		imp := l.stack[i]
+		d.WithSecondary(diag.SpanOf(imp.Decl.Path), "%s imports %s", l.from[i].Path, l.from[i+1].Path)
+	}
+	l.diagnostics.Add(d)
+}
+
+// returns the files named by path: the file itself, or the
+// .meme files directly inside the directory path, sorted
+func expand(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".meme") {
+			names = append(names, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("directory contains no .meme files")
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// describes err without repeating the path it is about
+func reason(err error) string {
+	if e, ok := err.(*os.PathError); ok {
+		return e.Err.Error()
+	}
+	return err.Error()
+}
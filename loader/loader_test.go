@@ -0,0 +1,205 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/parser"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// writes the given files, keyed by their path relative to
+// dir
+func writeFiles(dir string, files map[string]string) {
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(src), 0644)).To(Succeed())
+	}
+}
+
+// returns the paths of the files of prog, relative to dir
+func paths(dir string, prog *Program) []string {
+	list := make([]string, len(prog.Files))
+	for i, f := range prog.Files {
+		rel, err := filepath.Rel(dir, f.Path)
+		Expect(err).NotTo(HaveOccurred())
+		list[i] = filepath.ToSlash(rel)
+	}
+	return list
+}
+
+var _ = Describe("Load", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "meme-loader")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("Should load imported files and directories in dependency order", func() {
+		writeFiles(dir, map[string]string{
+			"board.meme":        "import \"issue.meme\"\nimport \"model\"\nconcept Board {}",
+			"issue.meme":        "import \"model/person.meme\"\nconcept Issue {}",
+			"model/person.meme": "concept Person {}",
+			"model/team.meme":   "import \"person.meme\"\nconcept Team {}",
+			"model/notes.txt":   "not a meme file",
+			"unused.meme":       "concept Unused {}",
+		})
+
+		prog, err := Load(token.NewFileSet(), filepath.Join(dir, "board.meme"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths(dir, prog)).To(Equal([]string{
+			"model/person.meme",
+			"issue.meme",
+			"model/team.meme",
+			"board.meme",
+		}))
+
+		board := prog.File(filepath.Join(dir, "board.meme"))
+		Expect(board.Imports).To(HaveLen(2))
+		Expect(board.Imports[1].Files).To(HaveLen(2))
+		Expect(prog.ASTs()[3]).To(Equal(board.AST))
+		Expect(prog.Sources).To(HaveKeyWithValue(board.Path, "import \"issue.meme\"\nimport \"model\"\nconcept Board {}"))
+	})
+
+	It("Should load every file of a directory given as a path", func() {
+		writeFiles(dir, map[string]string{
+			"a.meme": "import \"b.meme\"\nconcept A {}",
+			"b.meme": "concept B {}",
+		})
+
+		prog, err := Load(token.NewFileSet(), dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths(dir, prog)).To(Equal([]string{"b.meme", "a.meme"}))
+	})
+
+	It("Should read a file named by several paths once", func() {
+		writeFiles(dir, map[string]string{
+			"a.meme": "import \"b.meme\"\nconcept A {}",
+			"b.meme": "concept B {}",
+		})
+
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		rel, err := filepath.Rel(wd, filepath.Join(dir, "b.meme"))
+		Expect(err).NotTo(HaveOccurred())
+
+		prog, err := Load(token.NewFileSet(), filepath.Join(dir, "a.meme"), rel)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths(dir, prog)).To(Equal([]string{"b.meme", "a.meme"}))
+		Expect(prog.Files[0].Path).To(Equal(filepath.Join(dir, "b.meme")))
+	})
+
+	It("Should report imports of files and directories that do not exist", func() {
+		writeFiles(dir, map[string]string{
+			"a.meme":     "import \"missing.meme\"\nimport \"empty\"\nconcept A {}",
+			"empty/x.md": "",
+		})
+
+		fset := token.NewFileSet()
+		prog, err := Load(fset, filepath.Join(dir, "a.meme"), filepath.Join(dir, "b.meme"))
+		Expect(err).To(HaveOccurred())
+		Expect(prog.Files).To(HaveLen(1))
+
+		list := err.(diag.List)
+		Expect(list).To(HaveLen(3))
+		for _, d := range list {
+			Expect(d.Code).To(Equal(diag.CodeImportNotFound))
+		}
+
+		Expect(list[0].Message).To(Equal("cannot load " + filepath.Join(dir, "b.meme") + ": no such file or directory"))
+		Expect(list[1].Message).To(Equal("cannot import \"missing.meme\": " + filepath.Join(dir, "missing.meme") +
+			": no such file or directory"))
+		Expect(list[1].Position().Line).To(Equal(1))
+		Expect(list[1].Position().Column).To(Equal(8))
+		Expect(list[2].Message).To(Equal("cannot import \"empty\": " + filepath.Join(dir, "empty") +
+			": directory contains no .meme files"))
+	})
+
+	It("Should report import cycles", func() {
+		writeFiles(dir, map[string]string{
+			"a.meme": "import \"b.meme\"\nconcept A {}",
+			"b.meme": "import \"c.meme\"\nconcept B {}",
+			"c.meme": "import \"a.meme\"\nconcept C {}",
+		})
+
+		prog, err := Load(token.NewFileSet(), filepath.Join(dir, "a.meme"))
+		Expect(err).To(HaveOccurred())
+		Expect(prog.Files).To(HaveLen(3))
+
+		list := err.(diag.List)
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Code).To(Equal(diag.CodeImportCycle))
+		Expect(list[0].Message).To(Equal("import cycle: " +
+			filepath.Join(dir, "a.meme") + " -> " +
+			filepath.Join(dir, "b.meme") + " -> " +
+			filepath.Join(dir, "c.meme") + " -> " +
+			filepath.Join(dir, "a.meme")))
+		Expect(list[0].Position().Filename).To(Equal(filepath.Join(dir, "c.meme")))
+		Expect(list[0].Secondary).To(HaveLen(2))
+	})
+
+	It("Should keep the syntax errors of every file", func() {
+		writeFiles(dir, map[string]string{
+			"a.meme": "import \"b.meme\"\nconcept A {",
+			"b.meme": "concept B { required }",
+		})
+
+		_, err := Load(token.NewFileSet(), filepath.Join(dir, "a.meme"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(diag.List)).To(HaveLen(2))
+	})
+
+	It("Should resolve imports of the builtin library in its directory", func() {
+		writeFiles(dir, map[string]string{
+			"lib/time.meme":             "concept Time {}",
+			"project/schema/event.meme": "import \"builtin/time.meme\"\nconcept Event {}",
+		})
+
+		prog, err := Config{Builtin: filepath.Join(dir, "lib")}.Load(token.NewFileSet(), filepath.Join(dir, "project/schema/event.meme"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths(dir, prog)).To(Equal([]string{"lib/time.meme", "project/schema/event.meme"}))
+
+		// without a builtin library, the import is reported
+		// rather than read next to the importing file
+		prog, err = Load(token.NewFileSet(), filepath.Join(dir, "project/schema/event.meme"))
+		Expect(paths(dir, prog)).To(Equal([]string{"project/schema/event.meme"}))
+		list := err.(diag.List)
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Code).To(Equal(diag.CodeImportNotFound))
+		Expect(list[0].Message).To(Equal(`cannot import "builtin/time.meme": the builtin library was not found`))
+		Expect(list[0].Notes).To(Equal([]string{"give its directory with --builtin or MEME_BUILTIN"}))
+	})
+
+	It("Should resolve import paths against the importing file or the builtin library", func() {
+		f, err := parser.ParseSource(token.NewFileSet(), "a.meme", `import "../builtin"
+			import "builtin/time.meme"
+			import "builtin/special"`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(Target(f.Imports[0], "examples/board.meme", "")).To(Equal("builtin"))
+		Expect(Target(f.Imports[0], "examples/board.meme", "lib")).To(Equal("builtin"))
+		Expect(Target(f.Imports[1], "examples/deadline.meme", "/usr/lib/meme")).To(Equal("/usr/lib/meme/time.meme"))
+		Expect(Target(f.Imports[2], "examples/deadline.meme", "../builtin")).To(Equal(filepath.Join("..", "builtin", "special")))
+		Expect(Target(f.Imports[1], "examples/deadline.meme", "")).To(Equal(filepath.Join("examples", "builtin", "time.meme")))
+	})
+
+	It("Should load the bundled examples with the builtin files they import", func() {
+		prog, err := Config{Builtin: "../builtin"}.Load(token.NewFileSet(), "../examples/todolist/todolist.meme")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prog.File("../builtin/typedlist.meme")).NotTo(BeNil())
+		Expect(prog.File("../builtin/special/relation.meme")).NotTo(BeNil())
+		Expect(prog.Files[len(prog.Files)-1].Path).To(Equal("../examples/todolist/todolist.meme"))
+	})
+})
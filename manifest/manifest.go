@@ -0,0 +1,222 @@
+// Package manifest reads meme.yaml, the manifest at the root
+// of a meme project. It names the source roots of the
+// project, the version of the language it is written in, the
+// local projects it depends on, the code generation targets
+// and the settings of the lint rules:
+//
+//	language: "0.1"
+//	sources:
+//	  - schema/...
+//	exclude:
+//	  - generated
+//	dependencies:
+//	  - name: common
+//	    path: ../common
+//	generate:
+//	  - target: go
+//	    output: gen/go
+//	    options:
+//	      package: model
+//	lint:
+//	  unused-import: warning
+//	  missing-doc: off
+//
+// Paths in the manifest are relative to the directory it is
+// in, the root of the project.
+package manifest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the name of the manifest file.
+const FileName = "meme.yaml"
+
+// LanguageVersion is the version of the language that this
+// implementation reads. A manifest without a language
+// version is taken to be written in it.
+const LanguageVersion = "0.1"
+
+// the levels a lint rule can be set to
+var lintLevels = map[string]bool{"error": true, "warning": true, "off": true}
+
+// Manifest is the configuration of a meme project.
+type Manifest struct {
+	Path string `yaml:"-"` // the path of the manifest file
+
+	Language     string            `yaml:"language"`
+	Sources      []string          `yaml:"sources"` // input patterns, as given to meme build; ./... if empty
+	Include      []string          `yaml:"include"`
+	Exclude      []string          `yaml:"exclude"`
+	Builtin      string            `yaml:"builtin"` // the directory of the builtin library, looked up if empty
+	Dependencies []*Dependency     `yaml:"dependencies"`
+	Generate     []*Generator      `yaml:"generate"`
+	Lint         map[string]string `yaml:"lint"` // the level of lint rules, by name
+}
+
+// Dependency is a local project the project depends on. Its
+// files are built along with the files of the project.
+type Dependency struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"` // the directory of the project
+}
+
+// Generator is a code generation target with the options
+// given to its generator.
+type Generator struct {
+	Target  string                 `yaml:"target"`
+	Output  string                 `yaml:"output"` // the directory the code is written to
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// Dir returns the root directory of the project.
+func (m *Manifest) Dir() string { return filepath.Dir(m.Path) }
+
+// Find returns the path of the manifest in dir or in the
+// closest of its parents that has one, or "" if there is no
+// such manifest.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load finds the manifest of the project dir is in, as Find
+// does, and reads it. Returns nil if there is no manifest.
+func Load(dir string) (*Manifest, error) {
+	path, err := Find(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return Read(path)
+}
+
+// Read reads and checks the manifest at path. Unknown keys
+// are errors, so that misspelled settings are not ignored.
+func Read(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	m.Path = path
+
+	if err := m.check(); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return m, nil
+}
+
+// checks the settings that the types of the fields do not
+func (m *Manifest) check() error {
+	if m.Language == "" {
+		m.Language = LanguageVersion
+	}
+	if m.Language != LanguageVersion {
+		return fmt.Errorf("unsupported language version %q, this meme reads version %q", m.Language, LanguageVersion)
+	}
+
+	names := make(map[string]bool)
+	for i, dep := range m.Dependencies {
+		switch {
+		case dep.Path == "":
+			return fmt.Errorf("dependency %d has no path", i+1)
+		case dep.Name != "" && names[dep.Name]:
+			return fmt.Errorf("dependency `%s` listed twice", dep.Name)
+		}
+		names[dep.Name] = true
+	}
+
+	for i, gen := range m.Generate {
+		if gen.Target == "" {
+			return fmt.Errorf("generator %d has no target", i+1)
+		}
+	}
+
+	for rule, level := range m.Lint {
+		if !lintLevels[level] {
+			return fmt.Errorf("lint rule `%s` is set to %q, expected error, warning or off", rule, level)
+		}
+	}
+	return nil
+}
+
+// Inputs returns the input patterns of the project and of its
+// dependencies, joined with the directories of their
+// projects. A dependency with a manifest of its own
+// contributes its sources, any other one its whole tree.
+func (m *Manifest) Inputs() ([]string, error) {
+	return m.inputs(make(map[string]bool))
+}
+
+// returns the input patterns of the project, skipping the
+// projects already in seen, by manifest path
+func (m *Manifest) inputs(seen map[string]bool) ([]string, error) {
+	seen[m.Path] = true
+
+	sources := m.Sources
+	if len(sources) == 0 {
+		sources = []string{"./..."}
+	}
+
+	patterns := make([]string, 0, len(sources))
+	for _, source := range sources {
+		patterns = append(patterns, m.Join(source))
+	}
+
+	for _, dep := range m.Dependencies {
+		dir := m.Join(dep.Path)
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err != nil {
+			patterns = append(patterns, filepath.Join(dir, "..."))
+			continue
+		}
+
+		if seen[path] {
+			continue
+		}
+		other, err := Read(path)
+		if err != nil {
+			return nil, err
+		}
+		more, err := other.inputs(seen)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, more...)
+	}
+	return patterns, nil
+}
+
+// Join joins path, relative to the root of the project, with
+// the root. Absolute paths are returned as they are.
+func (m *Manifest) Join(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.Dir(), path)
+}
@@ -0,0 +1,138 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// writes the given files, keyed by their path relative to
+// dir
+func writeFiles(dir string, files map[string]string) {
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(src), 0644)).To(Succeed())
+	}
+}
+
+var _ = Describe("Manifest", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "meme-manifest")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("Should read every setting", func() {
+		writeFiles(dir, map[string]string{FileName: `
+language: 0.1
+sources:
+  - schema/...
+exclude:
+  - generated
+builtin: lib/builtin
+dependencies:
+  - name: common
+    path: ../common
+generate:
+  - target: go
+    output: gen/go
+    options:
+      package: model
+lint:
+  unused-import: warning
+  missing-doc: off
+`})
+
+		m, err := Read(filepath.Join(dir, FileName))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Dir()).To(Equal(dir))
+		Expect(m.Language).To(Equal("0.1"))
+		Expect(m.Sources).To(Equal([]string{"schema/..."}))
+		Expect(m.Exclude).To(Equal([]string{"generated"}))
+		Expect(m.Join(m.Builtin)).To(Equal(filepath.Join(dir, "lib/builtin")))
+		Expect(m.Dependencies).To(Equal([]*Dependency{{Name: "common", Path: "../common"}}))
+		Expect(m.Generate).To(HaveLen(1))
+		Expect(m.Generate[0].Target).To(Equal("go"))
+		Expect(m.Generate[0].Options).To(HaveKeyWithValue("package", "model"))
+		Expect(m.Lint).To(Equal(map[string]string{"unused-import": "warning", "missing-doc": "off"}))
+	})
+
+	It("Should find the manifest in a parent directory", func() {
+		writeFiles(dir, map[string]string{
+			FileName:               "sources: [schema]",
+			"schema/deep/a.meme":   "",
+			"other/meme.yaml/x.md": "",
+		})
+
+		path, err := Find(filepath.Join(dir, "schema", "deep"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal(filepath.Join(dir, FileName)))
+
+		// a directory named like the manifest is not one
+		path, err = Find(filepath.Join(dir, "other"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal(filepath.Join(dir, FileName)))
+
+		m, err := Load(filepath.Join(dir, "schema"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Sources).To(Equal([]string{"schema"}))
+	})
+
+	It("Should return the inputs of the project and of its dependencies", func() {
+		writeFiles(dir, map[string]string{
+			"app/" + FileName: `
+dependencies:
+  - path: ../common
+  - path: ../plain`,
+			"common/" + FileName: `
+sources: [types]
+dependencies:
+  - path: ../app`,
+			"plain/a.meme": "",
+		})
+
+		m, err := Read(filepath.Join(dir, "app", FileName))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Inputs()).To(Equal([]string{
+			filepath.Join(dir, "app", "..."),
+			filepath.Join(dir, "common", "types"),
+			filepath.Join(dir, "plain", "..."),
+		}))
+	})
+
+	It("Should report invalid manifests", func() {
+		for src, message := range map[string]string{
+			"language: 2.0":                                          `unsupported language version "2.0", this meme reads version "0.1"`,
+			"source: [schema]":                                       "unmarshal errors:\n  line 1: field source not found in type manifest.Manifest",
+			"dependencies: [{name: a}]":                              "dependency 1 has no path",
+			"generate: [{output: gen}]":                              "generator 1 has no target",
+			"lint: {missing-doc: loud}":                              "lint rule `missing-doc` is set to \"loud\", expected error, warning or off",
+			"dependencies: [{name: a, path: b}, {name: a, path: c}]": "dependency `a` listed twice",
+		} {
+			path := filepath.Join(dir, FileName)
+			writeFiles(dir, map[string]string{FileName: src})
+
+			_, err := Read(path)
+			Expect(err).To(MatchError(path + ": " + message))
+		}
+
+		_, err := Load(dir)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should return no manifest outside of a project", func() {
+		m, err := Load(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(BeNil())
+	})
+})
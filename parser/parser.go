@@ -1,13 +1,580 @@
 package parser
 
 import (
+	"fmt"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
 	"github.com/riyanshkarani011235/meme/lexer"
+	"github.com/riyanshkarani011235/meme/token"
 )
 
+// bailout is used as a panic value to unwind the parser
+// after a syntax error. It is recovered at the enclosing
+// field or declaration, which then skips ahead to a token
+// it can continue from.
+type bailout struct{}
+
 type Parser struct {
-	l *lexer.Lexer
+	l           *lexer.Lexer
+	tok         token.Token // the current token
+	diagnostics diag.List
+
+	// comments
+	lineEnd  int               // the line the previous token ends on
+	comments []*ast.Comment    // the comments read since the previous token
+	doc      *ast.CommentGroup // the comments right above the current token, if any
 }
 
 func NewParser(l *lexer.Lexer) *Parser {
 	return &Parser{l: l}
 }
+
+// ParseSource adds a file named filename to fset and parses
+// src as its contents. The name is used in diagnostics.
+func ParseSource(fset *token.FileSet, filename string, src string) (*ast.File, error) {
+	return NewParser(lexer.NewLexerForFile(fset, filename, filename, src)).ParseFile()
+}
+
+// Parses the entire input of the lexer and returns the
+// resulting tree. The parser recovers from syntax errors by
+// skipping to the next field or declaration, so that every
+// error in the file is reported. If there are errors, the
+// returned error is a diag.List and the tree only holds the
+// declarations that could be parsed.
+func (p *Parser) ParseFile() (*ast.File, error) {
+	p.next()
+	f := p.parseFile()
+	f.Name = p.l.File().Name()
+
+	p.diagnostics.Sort()
+	return f, p.diagnostics.Err()
+}
+
+// ----------------
+// helper functions
+// ----------------
+
+// advances to the next token, skipping over comments. The
+// comments right above the token are kept in p.doc.
+func (p *Parser) next() {
+	for {
+		t, ok := p.l.NextToken()
+		if !ok {
+			// the lexer is done, which means the previous
+			// token was either EOF or an error. Stay there.
+			return
+		}
+
+		switch t.Type {
+		case token.TokenSingleLineComment, token.TokenMultiLineComment:
+			p.comment(t)
+			continue
+		case token.TokenError:
+			// the lexer skips over the bad input and carries
+			// on, so the parser does the same
+			p.diagnostics.Add(diag.Errorf(p.l.FileSet(), diag.TokenSpan(t), diag.CodeLexical, "%s", t.Literal))
+			continue
+		}
+
+		p.doc = p.takeDoc(t)
+		p.lineEnd = p.line(t.End())
+		p.tok = t
+		return
+	}
+}
+
+// records a comment. Comments separated by an empty line
+// start a new group, and comments on the line of the
+// previous token are not part of any group.
+func (p *Parser) comment(t token.Token) {
+	start := p.line(t.Pos)
+	if start == p.lineEnd {
+		return
+	}
+
+	if n := len(p.comments); n > 0 && start > p.line(p.comments[n-1].End())+1 {
+		p.comments = nil
+	}
+	p.comments = append(p.comments, &ast.Comment{Slash: t.Pos, Text: t.Literal})
+}
+
+// returns the group of comments ending on the line above
+// token t, if any, and forgets the comments read so far
+func (p *Parser) takeDoc(t token.Token) *ast.CommentGroup {
+	comments := p.comments
+	p.comments = nil
+
+	if n := len(comments); n > 0 && p.line(comments[n-1].End())+1 == p.line(t.Pos) {
+		return &ast.CommentGroup{List: comments}
+	}
+	return nil
+}
+
+// returns the line of position pos
+func (p *Parser) line(pos token.Pos) int {
+	return p.l.FileSet().Position(pos).Line
+}
+
+// records a syntax error at token t and unwinds to the
+// enclosing field or declaration
+func (p *Parser) error(t token.Token, code diag.Code, format string, args ...interface{}) {
+	p.diagnostics.Add(diag.Errorf(p.l.FileSet(), diag.TokenSpan(t), code, format, args...))
+	panic(bailout{})
+}
+
+// runs parse, and if it fails with a syntax error, skips
+// tokens until one of the stop tokens (or EOF) is reached.
+// Returns false if parse failed.
+func (p *Parser) try(parse func(), stop ...token.TokenType) (ok bool) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if _, isBailout := r.(bailout); !isBailout {
+			panic(r)
+		}
+
+		ok = false
+		for !p.at(stop...) && p.tok.Type != token.TokenEOF {
+			p.next()
+		}
+	}()
+
+	parse()
+	return true
+}
+
+// reports whether the current token is of one of the types
+func (p *Parser) at(types ...token.TokenType) bool {
+	for _, tokenType := range types {
+		if p.tok.Type == tokenType {
+			return true
+		}
+	}
+	return false
+}
+
+// checks that the current token is of the expected type,
+// consumes it and returns its position
+func (p *Parser) expect(tokenType token.TokenType, what string) token.Pos {
+	t := p.tok
+	if t.Type != tokenType {
+		p.error(t, diag.CodeUnexpectedToken, "expected %s, found %s", what, describe(t))
+	}
+
+	p.next()
+	return t.Pos
+}
+
+// consumes the current token if it is of the given type
+func (p *Parser) accept(tokenType token.TokenType) bool {
+	if p.tok.Type != tokenType {
+		return false
+	}
+
+	p.next()
+	return true
+}
+
+func describe(t token.Token) string {
+	switch t.Type {
+	case token.TokenEOF:
+		return "end of file"
+	case token.TokenIdentifier:
+		return fmt.Sprintf("identifier `%s`", t.Literal)
+	default:
+		return fmt.Sprintf("`%s`", t.Literal)
+	}
+}
+
+// ------------
+// Declarations
+// ------------
+
+func (p *Parser) parseFile() *ast.File {
+	f := &ast.File{}
+	if p.at(token.TokenPackage) {
+		p.try(func() {
+			f.Package = &ast.PackageClause{Package: p.tok.Pos}
+			p.next()
+			f.Package.Name = p.parseIdent()
+		}, token.TokenImport, token.TokenConcept, token.TokenEnum, token.TokenRelation, token.TokenAt)
+	}
+
+	for p.at(token.TokenImport) {
+		p.try(func() {
+			f.Imports = append(f.Imports, p.parseImportDecl())
+		}, token.TokenImport, token.TokenConcept, token.TokenEnum, token.TokenRelation, token.TokenAt)
+	}
+
+	for p.tok.Type != token.TokenEOF {
+		start := p.tok
+		p.try(func() {
+			p.parseDecl(f)
+		}, token.TokenConcept, token.TokenEnum, token.TokenRelation, token.TokenAt)
+
+		if p.tok == start {
+			// no progress was made, e.g. the declaration
+			// failed on its first token
+			p.next()
+		}
+	}
+
+	f.EOF = p.tok.Pos
+	return f
+}
+
+// import Name "path"
+func (p *Parser) parseImportDecl() *ast.ImportDecl {
+	d := &ast.ImportDecl{Import: p.expect(token.TokenImport, "`import`")}
+	if p.at(token.TokenIdentifier) {
+		d.Name = p.parseIdent()
+	}
+
+	t := p.tok
+	p.expect(token.TokenStringLiteral, "import path")
+	d.Path = &ast.BasicLit{ValuePos: t.Pos, Kind: t.Type, Value: t.Literal}
+	return d
+}
+
+// parses a concept, an enum or a relation declaration and
+// adds it to f
+func (p *Parser) parseDecl(f *ast.File) {
+	doc := p.doc
+	annotations := p.parseAnnotations()
+
+	switch p.tok.Type {
+	case token.TokenConcept:
+		d := p.parseConceptDecl(annotations)
+		d.Doc = doc
+		f.Decls = append(f.Decls, d)
+		f.Concepts = append(f.Concepts, d)
+	case token.TokenEnum:
+		d := p.parseEnumDecl(annotations)
+		d.Doc = doc
+		f.Decls = append(f.Decls, d)
+		f.Enums = append(f.Enums, d)
+	case token.TokenRelation:
+		d := p.parseRelationDecl(annotations)
+		d.Doc = doc
+		f.Decls = append(f.Decls, d)
+		f.Relations = append(f.Relations, d)
+	case token.TokenImport:
+		p.error(p.tok, diag.CodeUnexpectedToken, "imports must come before the declarations of the file")
+	case token.TokenPackage:
+		p.error(p.tok, diag.CodeUnexpectedToken, "the package clause must come first in the file")
+	default:
+		p.error(p.tok, diag.CodeUnexpectedToken, "expected `concept`, `enum` or `relation`, found %s", describe(p.tok))
+	}
+}
+
+// @annotations... concept Name<T, ...> extends Base<...> { fields... }
+func (p *Parser) parseConceptDecl(annotations []*ast.Annotation) *ast.ConceptDecl {
+	d := &ast.ConceptDecl{Annotations: annotations}
+	d.Concept = p.expect(token.TokenConcept, "`concept`")
+	d.Name = p.parseIdent()
+
+	if p.accept(token.TokenLeftAngleBrace) {
+		d.TypeParams = append(d.TypeParams, p.parseIdent())
+		for p.accept(token.TokenComma) {
+			d.TypeParams = append(d.TypeParams, p.parseIdent())
+		}
+		p.expect(token.TokenRightAngleBrace, "`>`")
+	}
+
+	if p.accept(token.TokenExtends) {
+		d.Extends = p.parseNamedType()
+	}
+
+	d.Fields, d.Rbrace = p.parseBody()
+	return d
+}
+
+// @annotations... relation Name from one|many A to one|many B properties... { fields... }
+func (p *Parser) parseRelationDecl(annotations []*ast.Annotation) *ast.RelationDecl {
+	d := &ast.RelationDecl{Annotations: annotations}
+	d.Relation = p.expect(token.TokenRelation, "`relation`")
+	d.Name = p.parseIdent()
+	d.From = p.parseRelationEnd("from")
+	d.To = p.parseRelationEnd("to")
+
+	for p.at(token.TokenIdentifier) {
+		d.Properties = append(d.Properties, p.parseRelationProperty())
+	}
+
+	d.Fields, d.Rbrace = p.parseBody()
+	return d
+}
+
+// from|to one|many Type, where the cardinality is optional.
+// "one" and "many" are names of concepts unless they are
+// followed by one.
+func (p *Parser) parseRelationEnd(keyword string) *ast.RelationEnd {
+	e := &ast.RelationEnd{Keyword: p.parseWord(keyword)}
+
+	name := p.parseIdent()
+	if (name.Name == "one" || name.Name == "many") && p.at(token.TokenIdentifier) && !(keyword == "from" && p.tok.Literal == "to") {
+		e.Cardinality, name = name, p.parseIdent()
+	}
+	e.Type = p.parseNamedTypeFrom(name)
+
+	return e
+}
+
+// symmetric, transitive, acyclic or inverse of Relation
+func (p *Parser) parseRelationProperty() *ast.RelationProperty {
+	t := p.tok
+	switch t.Literal {
+	case "symmetric", "transitive", "acyclic":
+		return &ast.RelationProperty{Name: p.parseIdent()}
+	case "inverse":
+		prop := &ast.RelationProperty{Name: p.parseIdent()}
+		p.parseWord("of")
+		prop.Of = p.parseNamedType()
+		return prop
+	}
+
+	p.error(t, diag.CodeUnexpectedToken, "expected a relation property or `{`, found %s", describe(t))
+	return nil
+}
+
+// { fields... }, returns the fields and the position of the
+// closing brace
+func (p *Parser) parseBody() ([]*ast.FieldDecl, token.Pos) {
+	var fields []*ast.FieldDecl
+
+	p.expect(token.TokenLeftBrace, "`{`")
+	for !p.at(token.TokenRightBrace, token.TokenEOF) {
+		p.try(func() {
+			fields = append(fields, p.parseFieldDecl())
+		}, token.TokenAt, token.TokenRequired, token.TokenOptional, token.TokenRightBrace, token.TokenConcept, token.TokenEnum, token.TokenRelation)
+
+		if p.at(token.TokenConcept, token.TokenEnum, token.TokenRelation) {
+			// the body was never closed, let the next
+			// declaration be parsed on its own
+			p.error(p.tok, diag.CodeUnexpectedToken, "expected `}`, found %s", describe(p.tok))
+		}
+	}
+	rbrace := p.expect(token.TokenRightBrace, "`}`")
+
+	return fields, rbrace
+}
+
+// @annotations... enum Name { Member, Member = Value, ... }
+func (p *Parser) parseEnumDecl(annotations []*ast.Annotation) *ast.EnumDecl {
+	d := &ast.EnumDecl{Annotations: annotations}
+	d.Enum = p.expect(token.TokenEnum, "`enum`")
+	d.Name = p.parseIdent()
+
+	p.expect(token.TokenLeftBrace, "`{`")
+	for !p.at(token.TokenRightBrace, token.TokenEOF) {
+		m := &ast.EnumMember{Doc: p.doc}
+		m.Name = p.parseIdent()
+		if p.accept(token.TokenAssign) {
+			m.Value = p.parseValue()
+		}
+		d.Members = append(d.Members, m)
+
+		if !p.accept(token.TokenComma) && !p.at(token.TokenRightBrace) {
+			p.error(p.tok, diag.CodeUnexpectedToken, "expected `,` or `}`, found %s", describe(p.tok))
+		}
+	}
+	d.Rbrace = p.expect(token.TokenRightBrace, "`}`")
+
+	return d
+}
+
+// @annotations... required|optional name Type = Default
+func (p *Parser) parseFieldDecl() *ast.FieldDecl {
+	d := &ast.FieldDecl{Doc: p.doc}
+	d.Annotations = p.parseAnnotations()
+	t := p.tok
+	d.Modifier = t.Pos
+
+	switch t.Type {
+	case token.TokenRequired:
+		d.Required = true
+	case token.TokenOptional:
+		d.Required = false
+	default:
+		p.error(t, diag.CodeUnexpectedToken, "expected `required`, `optional` or `}`, found %s", describe(t))
+	}
+	p.next()
+
+	d.Name = p.parseIdent()
+	d.Type = p.parseType()
+	if p.accept(token.TokenAssign) {
+		d.Default = p.parseValue()
+	}
+	return d
+}
+
+// @Name or @Name(Value, ...), any number of times
+func (p *Parser) parseAnnotations() []*ast.Annotation {
+	var list []*ast.Annotation
+	for p.at(token.TokenAt) {
+		a := &ast.Annotation{At: p.tok.Pos}
+		p.next()
+		a.Name = p.parseIdent()
+
+		if p.at(token.TokenLeftParen) {
+			a.Lparen = p.tok.Pos
+			p.next()
+			if !p.at(token.TokenRightParen) {
+				a.Args = p.parseValueList()
+			}
+			a.Rparen = p.expect(token.TokenRightParen, "`)`")
+		}
+		list = append(list, a)
+	}
+
+	return list
+}
+
+func (p *Parser) parseIdent() *ast.Ident {
+	t := p.tok
+	p.expect(token.TokenIdentifier, "identifier")
+	return &ast.Ident{NamePos: t.Pos, Name: t.Literal}
+}
+
+// parses an identifier that is used as a keyword here
+func (p *Parser) parseWord(word string) *ast.Ident {
+	t := p.tok
+	if t.Type != token.TokenIdentifier || t.Literal != word {
+		p.error(t, diag.CodeUnexpectedToken, "expected `%s`, found %s", word, describe(t))
+	}
+	return p.parseIdent()
+}
+
+// ----------------
+// Type expressions
+// ----------------
+
+func (p *Parser) parseType() ast.TypeExpr {
+	t := p.tok
+
+	switch t.Type {
+	case token.TokenIntegerType, token.TokenStringType, token.TokenBooleanType:
+		p.next()
+		return &ast.PrimitiveType{TypePos: t.Pos, Kind: t.Type, Literal: t.Literal}
+
+	case token.TokenIdentifier:
+		return p.parseNamedType()
+
+	case token.TokenLeftSquareBrace:
+		p.next()
+		elem := p.parseType()
+		rbrack := p.expect(token.TokenRightSquareBrace, "`]`")
+		return &ast.ListType{Lbrack: t.Pos, Elem: elem, Rbrack: rbrack}
+
+	case token.TokenLeftParen:
+		p.next()
+		elems := p.parseTypeList()
+		if len(elems) < 2 {
+			p.error(t, diag.CodeShortTuple, "a tuple must have at least two elements")
+		}
+		rparen := p.expect(token.TokenRightParen, "`)`")
+		return &ast.TupleType{Lparen: t.Pos, Elems: elems, Rparen: rparen}
+
+	case token.TokenOneOf:
+		p.next()
+		p.expect(token.TokenLeftParen, "`(`")
+		options := p.parseTypeList()
+		rparen := p.expect(token.TokenRightParen, "`)`")
+		return &ast.OneOfType{OneOf: t.Pos, Options: options, Rparen: rparen}
+
+	case token.TokenAnyOf:
+		p.next()
+		p.expect(token.TokenLeftParen, "`(`")
+		options := p.parseTypeList()
+		rparen := p.expect(token.TokenRightParen, "`)`")
+		return &ast.AnyOfType{AnyOf: t.Pos, Options: options, Rparen: rparen}
+
+	default:
+		p.error(t, diag.CodeUnexpectedToken, "expected type, found %s", describe(t))
+		return nil
+	}
+}
+
+// Name, Package.Name or either with <Type, ...>
+func (p *Parser) parseNamedType() *ast.NamedType {
+	return p.parseNamedTypeFrom(p.parseIdent())
+}
+
+// parses the rest of a named type starting with name
+func (p *Parser) parseNamedTypeFrom(name *ast.Ident) *ast.NamedType {
+	n := &ast.NamedType{Name: name}
+	if p.accept(token.TokenDot) {
+		n.Package, n.Name = n.Name, p.parseIdent()
+	}
+	if p.accept(token.TokenLeftAngleBrace) {
+		n.Args = p.parseTypeList()
+		n.Rangle = p.expect(token.TokenRightAngleBrace, "`>`")
+	}
+
+	return n
+}
+
+// Type, Type, ...
+func (p *Parser) parseTypeList() []ast.TypeExpr {
+	list := []ast.TypeExpr{p.parseType()}
+	for p.accept(token.TokenComma) {
+		list = append(list, p.parseType())
+	}
+
+	return list
+}
+
+// -----------
+// Expressions
+// -----------
+
+// a literal, the name of an enum member, [Value, ...] or
+// (Value, Value, ...)
+func (p *Parser) parseValue() ast.Expr {
+	t := p.tok
+
+	switch t.Type {
+	case token.TokenIdentifier:
+		return p.parseIdent()
+
+	case token.TokenIntegerLiteral, token.TokenFloatLiteral, token.TokenStringLiteral,
+		token.TokenTrue, token.TokenFalse:
+		p.next()
+		return &ast.BasicLit{ValuePos: t.Pos, Kind: t.Type, Value: t.Literal}
+
+	case token.TokenLeftSquareBrace:
+		p.next()
+		var elems []ast.Expr
+		if !p.at(token.TokenRightSquareBrace) {
+			elems = p.parseValueList()
+		}
+		rbrack := p.expect(token.TokenRightSquareBrace, "`]`")
+		return &ast.ListLit{Lbrack: t.Pos, Elems: elems, Rbrack: rbrack}
+
+	case token.TokenLeftParen:
+		p.next()
+		elems := p.parseValueList()
+		if len(elems) < 2 {
+			p.error(t, diag.CodeShortTuple, "a tuple must have at least two elements")
+		}
+		rparen := p.expect(token.TokenRightParen, "`)`")
+		return &ast.TupleLit{Lparen: t.Pos, Elems: elems, Rparen: rparen}
+
+	default:
+		p.error(t, diag.CodeUnexpectedToken, "expected value, found %s", describe(t))
+		return nil
+	}
+}
+
+// Value, Value, ...
+func (p *Parser) parseValueList() []ast.Expr {
+	list := []ast.Expr{p.parseValue()}
+	for p.accept(token.TokenComma) {
+		list = append(list, p.parseValue())
+	}
+
+	return list
+}
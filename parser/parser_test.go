@@ -0,0 +1,549 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/ast"
+	"github.com/riyanshkarani011235/meme/diag"
+	"github.com/riyanshkarani011235/meme/lexer"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+func parse(input string) (*ast.File, error) {
+	return NewParser(lexer.NewLexer(input)).ParseFile()
+}
+
+var _ = Describe("ParseFile", func() {
+	Context("Parsing a concept declaration", func() {
+		testString := `concept Hello<K, V> extends World<V> {
+				required foo [oneof(Concept, Relation)]
+				optional bar (K, anyof(integer, string))
+				required baz TypedList<boolean>
+				}`
+
+		It("Should build the declaration", func() {
+			f, err := parse(testString)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Concepts).To(HaveLen(1))
+
+			d := f.Concepts[0]
+			Expect(d.Name.Name).To(Equal("Hello"))
+			Expect(d.TypeParams).To(HaveLen(2))
+			Expect(d.TypeParams[0].Name).To(Equal("K"))
+			Expect(d.TypeParams[1].Name).To(Equal("V"))
+			Expect(d.Extends.Name.Name).To(Equal("World"))
+			Expect(d.Extends.Args).To(HaveLen(1))
+			Expect(d.Fields).To(HaveLen(3))
+		})
+
+		It("Should build the field types", func() {
+			f, err := parse(testString)
+			Expect(err).NotTo(HaveOccurred())
+			fields := f.Concepts[0].Fields
+
+			Expect(fields[0].Required).To(BeTrue())
+			Expect(fields[0].Name.Name).To(Equal("foo"))
+			list, ok := fields[0].Type.(*ast.ListType)
+			Expect(ok).To(BeTrue())
+			oneOf, ok := list.Elem.(*ast.OneOfType)
+			Expect(ok).To(BeTrue())
+			Expect(oneOf.Options).To(HaveLen(2))
+
+			Expect(fields[1].Required).To(BeFalse())
+			tuple, ok := fields[1].Type.(*ast.TupleType)
+			Expect(ok).To(BeTrue())
+			Expect(tuple.Elems).To(HaveLen(2))
+			anyOf, ok := tuple.Elems[1].(*ast.AnyOfType)
+			Expect(ok).To(BeTrue())
+			Expect(anyOf.Options[0].(*ast.PrimitiveType).Kind).To(Equal(token.TokenIntegerType))
+
+			named, ok := fields[2].Type.(*ast.NamedType)
+			Expect(ok).To(BeTrue())
+			Expect(named.Name.Name).To(Equal("TypedList"))
+			Expect(named.Args[0].(*ast.PrimitiveType).Kind).To(Equal(token.TokenBooleanType))
+		})
+	})
+
+	Context("Parsing default values", func() {
+		It("Should attach literals, lists and tuples to their fields", func() {
+			f, err := parse(`concept Task {
+					optional isDone boolean = false
+					optional tags [string] = ["a", "b"]
+					optional empty [integer] = []
+					optional pair (integer, string) = (-1, "one")
+					required title string
+				}`)
+			Expect(err).NotTo(HaveOccurred())
+			fields := f.Concepts[0].Fields
+
+			lit, ok := fields[0].Default.(*ast.BasicLit)
+			Expect(ok).To(BeTrue())
+			Expect(lit.Kind).To(Equal(token.TokenFalse))
+
+			list, ok := fields[1].Default.(*ast.ListLit)
+			Expect(ok).To(BeTrue())
+			Expect(list.Elems).To(HaveLen(2))
+			Expect(list.Elems[1].(*ast.BasicLit).Value).To(Equal(`"b"`))
+
+			Expect(fields[2].Default.(*ast.ListLit).Elems).To(BeNil())
+
+			tuple, ok := fields[3].Default.(*ast.TupleLit)
+			Expect(ok).To(BeTrue())
+			Expect(tuple.Elems[0].(*ast.BasicLit).Value).To(Equal("-1"))
+
+			Expect(fields[4].Default).To(BeNil())
+		})
+
+		It("Should report missing and malformed values", func() {
+			_, err := parse(`concept Task {
+					optional a integer =
+					optional b (integer, string) = (1)
+					optional c [integer] = [1, integer]
+				}`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"3:6: expected value, found `optional`",
+				"3:37: a tuple must have at least two elements",
+				"4:33: expected value, found `integer`",
+			}, "\n")))
+		})
+	})
+
+	Context("Parsing annotations", func() {
+		It("Should attach annotations to concepts and fields", func() {
+			f, err := parse(`@doc("a ticket") @since("1.2")
+				concept Ticket {
+					@pattern("^[A-Z]+-[0-9]+$") required name string
+					@min(0) @max(5) @ui_hidden
+					optional points integer
+					@deprecated() @length(1, 10) optional tags [string]
+				}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			d := f.Concepts[0]
+			Expect(d.Annotations).To(HaveLen(2))
+			Expect(d.Annotations[0].Name.Name).To(Equal("doc"))
+			Expect(d.Annotations[1].Args[0].(*ast.BasicLit).Value).To(Equal(`"1.2"`))
+
+			fields := d.Fields
+			Expect(fields[0].Annotations[0].Name.Name).To(Equal("pattern"))
+			Expect(fields[1].Annotations).To(HaveLen(3))
+			Expect(fields[1].Annotations[2].Args).To(BeNil())
+			Expect(fields[1].Annotations[2].Rparen.IsValid()).To(BeFalse())
+			Expect(fields[2].Annotations[0].Args).To(BeNil())
+			Expect(fields[2].Annotations[0].Rparen.IsValid()).To(BeTrue())
+			Expect(fields[2].Annotations[1].Args).To(HaveLen(2))
+		})
+
+		It("Should start declarations at their first annotation", func() {
+			fset := token.NewFileSet()
+			src := "@a concept A {\n\t@b(1, [2]) required x integer\n}"
+			f, err := ParseSource(fset, "a.meme", src)
+			Expect(err).NotTo(HaveOccurred())
+
+			text := func(n ast.Node) string {
+				file := fset.File(n.Pos())
+				return src[file.Offset(n.Pos()):file.Offset(n.End())]
+			}
+
+			Expect(text(f.Concepts[0])).To(Equal(src))
+			Expect(text(f.Concepts[0].Annotations[0])).To(Equal("@a"))
+			Expect(text(f.Concepts[0].Fields[0])).To(Equal("@b(1, [2]) required x integer"))
+			Expect(text(f.Concepts[0].Fields[0].Annotations[0])).To(Equal("@b(1, [2])"))
+		})
+
+		It("Should report malformed annotations", func() {
+			_, err := parse(`@ concept A {
+					@min(integer) required a integer
+					@max(1 optional b integer
+					@doc("ok") required c string
+				}`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"1:3: expected identifier, found `concept`",
+				"2:11: expected value, found `integer`",
+				"3:13: expected `)`, found `optional`",
+			}, "\n")))
+		})
+	})
+
+	Context("Parsing enum declarations", func() {
+		It("Should build the members with their values and doc comments", func() {
+			f, err := parse(`concept Task { optional status Status = Todo }
+
+				@doc("the state of a task")
+				enum Status {
+					// not started yet,
+					// or put back
+					Todo,
+
+					// a comment that is not attached
+
+					/* being
+					 * worked on */
+					InProgress = "in-progress", // not attached either
+					Done
+				}
+				enum Empty {}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Concepts).To(HaveLen(1))
+			Expect(f.Enums).To(HaveLen(2))
+
+			d := f.Enums[0]
+			Expect(d.Annotations[0].Name.Name).To(Equal("doc"))
+			Expect(d.Name.Name).To(Equal("Status"))
+			Expect(d.Members).To(HaveLen(3))
+
+			Expect(d.Members[0].Doc.Text()).To(Equal("not started yet,\nor put back"))
+			Expect(d.Members[0].Value).To(BeNil())
+			Expect(d.Members[1].Doc.Text()).To(Equal("being\nworked on"))
+			Expect(d.Members[1].Value.(*ast.BasicLit).Value).To(Equal(`"in-progress"`))
+			Expect(d.Members[2].Doc).To(BeNil())
+			Expect(f.Enums[1].Members).To(BeEmpty())
+
+			Expect(f.Concepts[0].Fields[0].Default.(*ast.Ident).Name).To(Equal("Todo"))
+		})
+
+		It("Should report malformed enums and parse the rest of the file", func() {
+			f, err := parse(`enum A { X Y }
+				enum B { X = }
+				enum { X }
+				concept C { required a string
+				enum D { X }`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"1:12: expected `,` or `}`, found identifier `Y`",
+				"2:18: expected value, found `}`",
+				"3:10: expected identifier, found `{`",
+				"5:5: expected `}`, found `enum`",
+			}, "\n")))
+			Expect(f.Enums).To(HaveLen(1))
+			Expect(f.Enums[0].Name.Name).To(Equal("D"))
+		})
+	})
+
+	Context("Parsing imports", func() {
+		It("Should collect the imports at the top of the file", func() {
+			f, err := parse(`// the model
+				import "issue.meme"
+				import "../builtin"
+				concept Board {}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Imports).To(HaveLen(2))
+			Expect(f.Imports[0].Value()).To(Equal("issue.meme"))
+			Expect(f.Imports[1].Value()).To(Equal("../builtin"))
+			Expect(f.Pos()).To(Equal(f.Imports[0].Pos()))
+		})
+
+		It("Should report malformed and misplaced imports", func() {
+			f, err := parse(`import issue
+				import "a.meme"
+				concept A {}
+				import "b.meme"
+				concept B {}
+				package late`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"2:5: expected import path, found `import`",
+				"4:5: imports must come before the declarations of the file",
+				"6:5: the package clause must come first in the file",
+			}, "\n")))
+			Expect(f.Imports).To(HaveLen(1))
+			Expect(f.Concepts).To(HaveLen(2))
+		})
+	})
+
+	Context("Parsing packages and qualified names", func() {
+		It("Should build the package clause, aliases and qualified names", func() {
+			f, err := parse(`package board
+				import "../scrum"
+				import tasks "../tasks"
+				concept Board extends scrum.Base<tasks.Item> {
+					required issues [scrum.Issue]
+					optional owner Person
+				}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.PackageName()).To(Equal("board"))
+			Expect(f.Pos()).To(Equal(f.Package.Pos()))
+			Expect(f.Imports[0].Name).To(BeNil())
+			Expect(f.Imports[1].Name.Name).To(Equal("tasks"))
+
+			d := f.Concepts[0]
+			Expect(d.Extends.QualifiedName()).To(Equal("scrum.Base"))
+			Expect(d.Extends.Pos()).To(Equal(d.Extends.Package.Pos()))
+			Expect(d.Extends.Args[0].(*ast.NamedType).QualifiedName()).To(Equal("tasks.Item"))
+			Expect(d.Fields[0].Type.(*ast.ListType).Elem.(*ast.NamedType).Package.Name).To(Equal("scrum"))
+			Expect(d.Fields[1].Type.(*ast.NamedType).Package).To(BeNil())
+		})
+
+		It("Should report malformed package clauses and qualified names", func() {
+			_, err := parse(`package "scrum"
+				concept A { required b scrum. }`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"1:9: expected identifier, found `\"scrum\"`",
+				"2:35: expected identifier, found `}`",
+			}, "\n")))
+		})
+	})
+
+	Context("Parsing relation declarations", func() {
+		It("Should build the ends and the fields", func() {
+			f, err := parse(`@doc("who works on an issue")
+				relation AssignedTo from many Issue to one scrum.User {
+					optional since Time
+				}
+				relation RelatedTo from Issue to Issue {}
+				relation Odd from one to many many {}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Relations).To(HaveLen(3))
+
+			d := f.Relations[0]
+			Expect(d.Annotations).To(HaveLen(1))
+			Expect(d.Name.Name).To(Equal("AssignedTo"))
+			Expect(d.From.Keyword.Name).To(Equal("from"))
+			Expect(d.From.Cardinality.Name).To(Equal("many"))
+			Expect(d.From.Type.QualifiedName()).To(Equal("Issue"))
+			Expect(d.To.Cardinality.Name).To(Equal("one"))
+			Expect(d.To.Type.QualifiedName()).To(Equal("scrum.User"))
+			Expect(d.Fields).To(HaveLen(1))
+			Expect(f.Pos()).To(Equal(d.Pos()))
+
+			Expect(f.Relations[1].From.Cardinality).To(BeNil())
+			Expect(f.Relations[1].Fields).To(BeEmpty())
+
+			// one and many are names of concepts unless a
+			// name follows them
+			odd := f.Relations[2]
+			Expect(odd.From.Cardinality).To(BeNil())
+			Expect(odd.From.Type.Name.Name).To(Equal("one"))
+			Expect(odd.To.Cardinality.Name).To(Equal("many"))
+			Expect(odd.To.Type.Name.Name).To(Equal("many"))
+		})
+
+		It("Should build the properties", func() {
+			f, err := parse(`relation Blocks from Issue to Issue acyclic transitive {}
+				relation BlockedBy from Issue to Issue inverse of scrum.Blocks {}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			props := f.Relations[0].Properties
+			Expect(props).To(HaveLen(2))
+			Expect(props[0].Name.Name).To(Equal("acyclic"))
+			Expect(props[0].Of).To(BeNil())
+			Expect(props[1].Name.Name).To(Equal("transitive"))
+
+			inverse := f.Relations[1].Properties[0]
+			Expect(inverse.Name.Name).To(Equal("inverse"))
+			Expect(inverse.Of.QualifiedName()).To(Equal("scrum.Blocks"))
+			Expect(inverse.End()).To(Equal(inverse.Of.End()))
+		})
+
+		It("Should report unknown properties", func() {
+			_, err := parse(`relation Blocks from Issue to Issue reflexive {}
+				relation BlockedBy from Issue to Issue inverse Blocks {}`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"1:37: expected a relation property or `{`, found identifier `reflexive`",
+				"2:52: expected `of`, found identifier `Blocks`",
+			}, "\n")))
+		})
+
+		It("Should keep from and to usable as names", func() {
+			f, err := parse(`concept Relation {
+					required from Concept
+					required to Concept
+				}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Concepts[0].Fields[1].Name.Name).To(Equal("to"))
+		})
+
+		It("Should report malformed relations", func() {
+			f, err := parse(`relation A to B {}
+				relation B from C {}
+				relation C from D to E
+				concept D {}`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"1:12: expected `from`, found identifier `to`",
+				"2:23: expected `to`, found `{`",
+				"4:5: expected `{`, found `concept`",
+			}, "\n")))
+			Expect(f.Relations).To(BeEmpty())
+			Expect(f.Concepts).To(HaveLen(1))
+		})
+	})
+
+	Context("Parsing multiple declarations and comments", func() {
+		It("Should keep the declarations in source order", func() {
+			f, err := parse(`enum Status { Open }
+				relation Blocks from Issue to Issue {}
+				concept Issue {}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Decls).To(Equal([]ast.Decl{f.Enums[0], f.Relations[0], f.Concepts[0]}))
+		})
+
+		It("Should skip comments", func() {
+			f, err := parse(`// a comment
+				concept A {}
+				/* another
+				   comment */
+				concept B extends A {}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Concepts).To(HaveLen(2))
+			Expect(f.Concepts[1].Extends.Name.Name).To(Equal("A"))
+		})
+
+		It("Should attach doc comments to declarations and fields", func() {
+			f, err := parse(`// a comment that is not attached
+
+				/// a unit of work
+				@since("1.0")
+				concept Issue {
+					// what the issue is about
+					required title string // not attached
+					/* how much work it is,
+					 * in points */
+					@min(0) optional points integer
+					optional done boolean
+				}
+				// the state of an issue
+				enum Status { Open }
+				// an issue that must be closed first
+				relation Blocks from Issue to Issue {}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			issue := f.Concepts[0]
+			Expect(issue.Doc.Text()).To(Equal("a unit of work"))
+			Expect(issue.Pos()).To(Equal(issue.Annotations[0].Pos()))
+			Expect(issue.Fields[0].Doc.Text()).To(Equal("what the issue is about"))
+			Expect(issue.Fields[1].Doc.Text()).To(Equal("how much work it is,\nin points"))
+			Expect(issue.Fields[2].Doc).To(BeNil())
+			Expect(f.Enums[0].Doc.Text()).To(Equal("the state of an issue"))
+			Expect(f.Relations[0].Doc.Text()).To(Equal("an issue that must be closed first"))
+		})
+	})
+
+	Context("Recording positions", func() {
+		It("Should give every node the positions of its first and last characters", func() {
+			fset := token.NewFileSet()
+			src := "concept A extends B<T> {\n\trequired x [oneof(T, string)]\n}\n"
+			f, err := ParseSource(fset, "a.meme", src)
+			Expect(err).NotTo(HaveOccurred())
+
+			text := func(n ast.Node) string {
+				file := fset.File(n.Pos())
+				return src[file.Offset(n.Pos()):file.Offset(n.End())]
+			}
+
+			d := f.Concepts[0]
+			Expect(text(d)).To(Equal(strings.TrimSpace(src)))
+			Expect(text(d.Extends)).To(Equal("B<T>"))
+			Expect(text(d.Fields[0])).To(Equal("required x [oneof(T, string)]"))
+			Expect(text(d.Fields[0].Type.(*ast.ListType).Elem)).To(Equal("oneof(T, string)"))
+			Expect(fset.Position(d.Fields[0].Name.Pos()).String()).To(Equal("a.meme:2:11"))
+			Expect(fset.Position(f.End()).String()).To(Equal("a.meme:4:1"))
+		})
+	})
+
+	Context("Parsing invalid input", func() {
+		It("Should report a missing field type", func() {
+			_, err := parse("concept A { required foo }")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected type"))
+		})
+
+		It("Should report a missing modifier", func() {
+			_, err := parse("concept A { foo string }")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected `required`, `optional` or `}`"))
+		})
+
+		It("Should report single element tuples", func() {
+			_, err := parse("concept A { required foo (string) }")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("at least two elements"))
+		})
+
+		It("Should report lexer errors", func() {
+			_, err := parse("concept A { required foo string ! }")
+			Expect(err).To(HaveOccurred())
+			Expect(err.(diag.List)).To(HaveLen(1))
+			Expect(err.(diag.List)[0].Code).To(Equal(diag.CodeLexical))
+		})
+	})
+
+	Context("Recovering from syntax errors", func() {
+		It("Should report every broken field and keep the rest", func() {
+			f, err := parse(`concept A {
+					required foo
+					optional bar [string
+					required baz string
+				}
+				concept B { required qux (string) }
+				concept C {}`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"3:6: expected type, found `optional`",
+				"4:6: expected `]`, found `required`",
+				"6:30: a tuple must have at least two elements",
+			}, "\n")))
+
+			Expect(f.Concepts).To(HaveLen(3))
+			Expect(f.Concepts[0].Fields).To(HaveLen(1))
+			Expect(f.Concepts[0].Fields[0].Name.Name).To(Equal("baz"))
+			Expect(f.Concepts[1].Fields).To(BeEmpty())
+		})
+
+		It("Should recover from a declaration that is never closed", func() {
+			f, err := parse(`concept A { required foo string
+				concept B {}`)
+			Expect(err).To(MatchError("2:5: expected `}`, found `concept`"))
+			Expect(f.Concepts).To(HaveLen(1))
+			Expect(f.Concepts[0].Name.Name).To(Equal("B"))
+		})
+
+		It("Should skip tokens outside of declarations", func() {
+			f, err := parse(`foo bar concept A {}`)
+			Expect(err).To(MatchError("1:1: expected `concept`, `enum` or `relation`, found identifier `foo`"))
+			Expect(f.Concepts).To(HaveLen(1))
+		})
+
+		It("Should report lexer errors and parse the rest of the file", func() {
+			f, err := parse(`concept A { required foo string # }
+				concept B { required bar / string }
+				concept C {}`)
+			Expect(err).To(MatchError(strings.Join([]string{
+				"1:33: unexpected character '#'",
+				"2:30: lone '/'",
+			}, "\n")))
+			Expect(f.Concepts).To(HaveLen(3))
+			Expect(f.Concepts[1].Fields).To(HaveLen(1))
+		})
+
+		It("Should name the file in diagnostics", func() {
+			_, err := ParseSource(token.NewFileSet(), "a.meme", "concept {}")
+			Expect(err).To(MatchError("a.meme:1:9: expected identifier, found `{`"))
+		})
+	})
+
+	Context("Parsing the bundled schemas", func() {
+		It("Should parse every file under builtin/ and examples/", func() {
+			files := make([]string, 0)
+			for _, root := range []string{"../builtin", "../examples"} {
+				err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+					if err == nil && strings.HasSuffix(path, ".meme") {
+						files = append(files, path)
+					}
+					return err
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(files).NotTo(BeEmpty())
+
+			for _, file := range files {
+				data, err := ioutil.ReadFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = parse(string(data))
+				Expect(err).NotTo(HaveOccurred(), file)
+			}
+		})
+	})
+})
@@ -0,0 +1,190 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pos is a compact encoding of a source position within a
+// FileSet. It can be converted into a Position with the
+// FileSet it belongs to. The zero value, NoPos, is not a
+// valid position.
+//
+// Each file of a FileSet occupies the range of positions
+// [base, base+size], so comparing two Pos values of the same
+// file compares their offsets.
+type Pos int
+
+const NoPos Pos = 0
+
+// IsValid reports whether the position is valid.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Position is a Pos converted into a file name, a line
+// and a column.
+type Position struct {
+	Filename string // the name used to refer to the file in messages
+	Offset   int    // byte offset, starting at 0
+	Line     int    // 1-based
+	Column   int    // 1-based, in characters
+}
+
+// IsValid reports whether the position is valid.
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String returns the position as file:line:column, line:column
+// if the file has no name, or "-" if the position is not valid.
+func (pos Position) String() string {
+	switch {
+	case !pos.IsValid():
+		return "-"
+	case pos.Filename == "":
+		return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	default:
+		return fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column)
+	}
+}
+
+// File is a file of a FileSet. It records the offsets at
+// which lines start, so that a Pos can be converted back
+// into a line and a column.
+type File struct {
+	name  string
+	path  string
+	base  int
+	size  int
+	lines []int  // offset of the first character of each line
+	src   string // the contents of the file, if set
+}
+
+func (f *File) Name() string { return f.name }
+func (f *File) Path() string { return f.path }
+func (f *File) Base() int    { return f.base }
+func (f *File) Size() int    { return f.size }
+
+// SetSource sets the contents of the file, which are used
+// to count columns in characters rather than in bytes.
+func (f *File) SetSource(src string) {
+	f.src = src
+}
+
+// LineCount returns the number of lines added so far.
+func (f *File) LineCount() int {
+	return len(f.lines)
+}
+
+// AddLine records that a line starts at offset. Offsets
+// must be added in increasing order, others are ignored.
+// A line may start at the end of the file, after its last
+// newline.
+func (f *File) AddLine(offset int) {
+	if n := len(f.lines); (n == 0 || f.lines[n-1] < offset) && offset <= f.size {
+		f.lines = append(f.lines, offset)
+	}
+}
+
+// Pos returns the Pos of the given offset in the file.
+func (f *File) Pos(offset int) Pos {
+	if offset > f.size {
+		panic(fmt.Sprintf("invalid offset %d (file size is %d)", offset, f.size))
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset returns the offset of p in the file.
+func (f *File) Offset(p Pos) int {
+	if int(p) < f.base || int(p) > f.base+f.size {
+		panic(fmt.Sprintf("invalid Pos value %d (should be in [%d, %d])", p, f.base, f.base+f.size))
+	}
+	return int(p) - f.base
+}
+
+// Position converts p into a Position by binary search over
+// the starts of the lines of the file.
+func (f *File) Position(p Pos) Position {
+	offset := f.Offset(p)
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	if i < 0 {
+		return Position{Filename: f.name, Offset: offset}
+	}
+
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     i + 1,
+		Column:   f.column(f.lines[i], offset),
+	}
+}
+
+// returns the 1-based column of offset in the line starting
+// at lineStart
+func (f *File) column(lineStart int, offset int) int {
+	if len(f.src) != f.size {
+		// no source, count bytes
+		return offset - lineStart + 1
+	}
+
+	// a byte order mark is not part of the first line
+	if lineStart == 0 && strings.HasPrefix(f.src, "\uFEFF") && offset >= len("\uFEFF") {
+		lineStart = len("\uFEFF")
+	}
+	return utf8.RuneCountInString(f.src[lineStart:offset]) + 1
+}
+
+// FileSet is a set of source files. Positions of tokens in
+// all the files of a set are represented as a single Pos.
+type FileSet struct {
+	base  int     // the base of the next file
+	files []*File // sorted by base
+}
+
+func NewFileSet() *FileSet {
+	// positions start at 1, so that NoPos is never valid
+	return &FileSet{base: 1}
+}
+
+// AddFile adds a file of the given size to the set and
+// returns it. Its first line starts at offset 0.
+func (s *FileSet) AddFile(name string, path string, size int) *File {
+	f := &File{name: name, path: path, base: s.base, size: size, lines: []int{0}}
+	s.files = append(s.files, f)
+
+	// +1 so that the position just past the end of a file
+	// is still part of it
+	s.base += size + 1
+	return f
+}
+
+// File returns the file that contains p, or nil if there
+// is no such file.
+func (s *FileSet) File(p Pos) *File {
+	if !p.IsValid() {
+		return nil
+	}
+
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i < 0 || int(p) > s.files[i].base+s.files[i].size {
+		return nil
+	}
+	return s.files[i]
+}
+
+// Position converts p into a Position. It returns the zero
+// Position if p is not a position of a file in the set.
+func (s *FileSet) Position(p Pos) Position {
+	if f := s.File(p); f != nil {
+		return f.Position(p)
+	}
+	return Position{}
+}
+
+// Files returns the files of the set in the order they were added.
+func (s *FileSet) Files() []*File {
+	return s.files
+}
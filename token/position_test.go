@@ -0,0 +1,68 @@
+package token
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileSet", func() {
+	// adds a file with the given contents and its lines
+	addFile := func(s *FileSet, name string, src string) *File {
+		f := s.AddFile(name, "/schemas/"+name, len(src))
+		for i := 0; i < len(src); i++ {
+			if src[i] == '\n' {
+				f.AddLine(i + 1)
+			}
+		}
+		return f
+	}
+
+	It("Should give every file its own range of positions", func() {
+		s := NewFileSet()
+		a := addFile(s, "a.meme", "concept A {}")
+		b := addFile(s, "b.meme", "concept B {}\n")
+
+		Expect(a.Pos(0)).To(Equal(Pos(1)))
+		Expect(b.Pos(0)).To(BeNumerically(">", a.Pos(a.Size())))
+		Expect(s.File(a.Pos(a.Size()))).To(Equal(a))
+		Expect(s.File(b.Pos(3))).To(Equal(b))
+		Expect(s.File(NoPos)).To(BeNil())
+		Expect(s.File(b.Pos(b.Size()) + 1)).To(BeNil())
+		Expect(s.Files()).To(Equal([]*File{a, b}))
+	})
+
+	It("Should convert positions into lines and columns", func() {
+		s := NewFileSet()
+		addFile(s, "a.meme", "concept A {}")
+		b := addFile(s, "b.meme", "concept B {\n\trequired c C\n}\n")
+
+		Expect(b.LineCount()).To(Equal(4))
+		Expect(s.Position(b.Pos(0)).String()).To(Equal("b.meme:1:1"))
+		Expect(s.Position(b.Pos(11)).String()).To(Equal("b.meme:1:12"))
+		Expect(s.Position(b.Pos(12)).String()).To(Equal("b.meme:2:1"))
+		Expect(s.Position(b.Pos(25)).String()).To(Equal("b.meme:2:14"))
+		Expect(s.Position(b.Pos(28))).To(Equal(Position{Filename: "b.meme", Offset: 28, Line: 4, Column: 1}))
+		Expect(s.Position(NoPos).String()).To(Equal("-"))
+	})
+
+	It("Should count columns in characters if the source is set", func() {
+		src := "\uFEFFa 名前\nü b"
+		f := addFile(NewFileSet(), "a.meme", src)
+		Expect(f.Position(f.Pos(8)).Column).To(Equal(9))
+
+		f.SetSource(src)
+		Expect(f.Position(f.Pos(3)).Column).To(Equal(1))
+		Expect(f.Position(f.Pos(8)).Column).To(Equal(4))
+		Expect(f.Position(f.Pos(15)).String()).To(Equal("a.meme:2:3"))
+	})
+
+	It("Should ignore lines added out of order", func() {
+		f := NewFileSet().AddFile("", "", 10)
+		f.AddLine(5)
+		f.AddLine(3)
+		f.AddLine(5)
+		f.AddLine(11)
+		Expect(f.LineCount()).To(Equal(2))
+		Expect(f.Position(f.Pos(4)).String()).To(Equal("1:5"))
+	})
+})
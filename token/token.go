@@ -4,18 +4,24 @@ import (
 	"fmt"
 )
 
-type FileInfo struct {
-	FileName string
-	FilePath string
+// Token is a lexical token. Its position is a single Pos,
+// which the FileSet of the lexer converts back into a file,
+// a line and a column.
+type Token struct {
+	Type    TokenType
+	Literal string
+	Pos     Pos // position of the first character
 }
 
-type Token struct {
-	Type              TokenType
-	Literal           string
-	FileInfo          *FileInfo
-	LineNumber        int
-	ColumnNumberStart int
-	ColumnNumberEnd   int
+// End returns the position just past the last character
+// of the token. EOF and error tokens are empty.
+func (token Token) End() Pos {
+	switch token.Type {
+	case TokenEOF, TokenError:
+		return token.Pos
+	default:
+		return token.Pos + Pos(len(token.Literal))
+	}
 }
 
 func (token Token) String() string {
@@ -23,8 +29,9 @@ func (token Token) String() string {
 	switch token.Type {
 	case TokenError:
 		return token.Literal
-	case TokenIdentifier, TokenSingleLineComment, TokenMultiLineComment:
-		// Identifiers / comments, print at most 10 characters
+	case TokenIdentifier, TokenIntegerLiteral, TokenFloatLiteral, TokenStringLiteral,
+		TokenSingleLineComment, TokenMultiLineComment:
+		// Identifiers / literals / comments, print at most 10 characters
 		if len(token.Literal) > 10 {
 			return fmt.Sprintf("%v(%.10v...)", token.Type, token.Literal)
 		} else {
@@ -16,6 +16,9 @@ const (
 	TokenRequired // required
 	TokenOptional // optional
 	TokenExtends  // extends
+	TokenEnum     // enum
+	TokenImport   // import
+	TokenPackage  // package
 
 	// identifier
 	TokenIdentifier
@@ -25,6 +28,13 @@ const (
 	TokenStringType  // string
 	TokenBooleanType // boolean
 
+	// literal values, Literal is the literal as written
+	TokenIntegerLiteral // 42, 1_000, 0x2A
+	TokenFloatLiteral   // 4.2, 1e-3
+	TokenStringLiteral  // "a\tb" or `raw`
+	TokenTrue           // true
+	TokenFalse          // false
+
 	// composite type constructors
 	TokenOneOf // oneof
 	TokenAnyOf // anyof
@@ -40,7 +50,14 @@ const (
 	TokenRightAngleBrace  // >
 
 	// delimiters
-	TokenComma // ,
+	TokenComma  // ,
+	TokenAssign // =
+
+	// annotations
+	TokenAt // @
+
+	// qualified names
+	TokenDot // .
 
 	// comments
 	TokenSingleLineComment
@@ -54,12 +71,19 @@ var TokenTypeLookupMap = map[string]TokenType{
 	"required": TokenRequired,
 	"optional": TokenOptional,
 	"extends":  TokenExtends,
+	"enum":     TokenEnum,
+	"import":   TokenImport,
+	"package":  TokenPackage,
 
 	// basic types / literals
 	"integer": TokenIntegerType,
 	"string":  TokenStringType,
 	"boolean": TokenBooleanType,
 
+	// boolean literals
+	"true":  TokenTrue,
+	"false": TokenFalse,
+
 	// composite type constructors
 	"oneof": TokenOneOf,
 	"anyof": TokenAnyOf,
@@ -76,6 +100,13 @@ var TokenTypeLookupMap = map[string]TokenType{
 
 	// delimiters
 	",": TokenComma,
+	"=": TokenAssign,
+
+	// annotations
+	"@": TokenAt,
+
+	// qualified names
+	".": TokenDot,
 }
 
 var tokenString = map[TokenType]string{
@@ -87,10 +118,18 @@ var tokenString = map[TokenType]string{
 	TokenRequired:          "REQUIRED",
 	TokenOptional:          "OPTIONAL",
 	TokenExtends:           "EXTENDS",
+	TokenEnum:              "ENUM",
+	TokenImport:            "IMPORT",
+	TokenPackage:           "PACKAGE",
 	TokenIdentifier:        "IDENTIFIER",
 	TokenIntegerType:       "INTEGER",
 	TokenStringType:        "STRING",
 	TokenBooleanType:       "BOOLEAN",
+	TokenIntegerLiteral:    "INTEGER_LITERAL",
+	TokenFloatLiteral:      "FLOAT_LITERAL",
+	TokenStringLiteral:     "STRING_LITERAL",
+	TokenTrue:              "TRUE",
+	TokenFalse:             "FALSE",
 	TokenOneOf:             "ONEOF",
 	TokenAnyOf:             "ANYOF",
 	TokenLeftParen:         "LEFT_PAREN",
@@ -102,6 +141,9 @@ var tokenString = map[TokenType]string{
 	TokenLeftAngleBrace:    "LEFT_ANGLE_BRACE",
 	TokenRightAngleBrace:   "RIGHT_ANGLE_BRACE",
 	TokenComma:             "COMMA",
+	TokenAssign:            "ASSIGN",
+	TokenAt:                "AT",
+	TokenDot:               "DOT",
 	TokenSingleLineComment: "SINGLE_LINE_COMMENT",
 	TokenMultiLineComment:  "MULTI_LINE_COMMENT",
 }
@@ -0,0 +1,215 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/riyanshkarani011235/meme/concept"
+)
+
+// The instances of a relation are edges between the values
+// of their from and to fields. Two values are the same
+// instance if they have the same JSON encoding. The edges of
+// every relation are collected across the whole instance,
+// then checked against the cardinalities and the properties
+// of the relation:
+//
+//   - if an end of a relation is one, an instance at the
+//     other end is related to at most one instance at it
+//   - an acyclic relation has no edge from an instance to
+//     itself, and its edges, with the edges of its inverse
+//     swapped, do not form a cycle
+//   - a symmetric relation that has an edge from a to b also
+//     has the edge from b to a
+//   - if an instance has edges of both a relation and its
+//     inverse, an edge from a to b of the one comes with an
+//     edge from b to a of the other
+//
+// Transitive relations imply edges that do not have to be
+// listed, and do not constrain their instances.
+
+// an instance of a relation found in the data
+type edge struct {
+	rel      *concept.Relation
+	from, to string // the keys of the ends
+	path     string // where the edge is in the data
+}
+
+// checks that edge, an instance of relation rel at path, does
+// not relate an instance to itself if rel or its inverse is
+// acyclic, and records it for checkRelations
+func (v *validator) checkEdge(rel *concept.Relation, object map[string]interface{}, path string) {
+	e := edge{rel: rel, from: key(object["from"]), to: key(object["to"]), path: path}
+	v.edges = append(v.edges, e)
+
+	if e.from != e.to {
+		return
+	}
+	if rel.Acyclic() {
+		v.errorf(path, "relation `%s` is acyclic, but the edge relates an instance to itself", rel)
+	} else if inverse := rel.Inverse(); inverse != nil && inverse.Acyclic() {
+		v.errorf(path, "relation `%s` is the inverse of `%s`, which is acyclic, but the edge relates an instance to itself", rel, inverse)
+	}
+}
+
+// checks the edges of every relation found in the data, in
+// the order the relations first appear
+func (v *validator) checkRelations() {
+	byRelation := make(map[*concept.Relation][]edge)
+	order := make([]*concept.Relation, 0)
+	for _, e := range v.edges {
+		if _, ok := byRelation[e.rel]; !ok {
+			order = append(order, e.rel)
+		}
+		byRelation[e.rel] = append(byRelation[e.rel], e)
+	}
+
+	for _, rel := range order {
+		edges := byRelation[rel]
+		inverse := rel.Inverse()
+
+		if rel.To().Cardinality == concept.One {
+			v.checkCardinality(edges, func(e edge) (string, string) { return e.from, e.to }, func(e, first edge) string {
+				return fmt.Sprintf("relation `%s` is to one `%s`, but %s is already related to %s at %s",
+					rel, rel.To().Concept, e.from, first.to, first.path)
+			})
+		}
+		if rel.From().Cardinality == concept.One {
+			v.checkCardinality(edges, func(e edge) (string, string) { return e.to, e.from }, func(e, first edge) string {
+				return fmt.Sprintf("relation `%s` is from one `%s`, but %s is already related from %s at %s",
+					rel, rel.From().Concept, e.to, first.from, first.path)
+			})
+		}
+
+		if rel.Symmetric() {
+			v.checkMirrored(edges, edges, func(e edge) string {
+				return fmt.Sprintf("relation `%s` is symmetric, but there is no edge from %s to %s", rel, e.to, e.from)
+			})
+		}
+
+		if inverse != nil && len(byRelation[inverse]) > 0 {
+			v.checkMirrored(edges, byRelation[inverse], func(e edge) string {
+				return fmt.Sprintf("relation `%s` is the inverse of `%s`, but there is no edge of `%s` from %s to %s",
+					rel, inverse, inverse, e.to, e.from)
+			})
+		}
+
+		if rel.Acyclic() {
+			v.checkCycles(rel, edges, byRelation[inverse])
+		}
+	}
+}
+
+// reports every edge of edges that relates an instance to
+// another instance than the first edge of that instance
+// does. ends returns the instance of an edge and the one it
+// is related to, and conflict the message for an edge and
+// the first edge of its instance.
+func (v *validator) checkCardinality(edges []edge, ends func(e edge) (string, string), conflict func(e, first edge) string) {
+	firsts := make(map[string]edge)
+	for _, e := range edges {
+		instance, related := ends(e)
+		first, ok := firsts[instance]
+		if !ok {
+			firsts[instance] = e
+			continue
+		}
+		if _, firstRelated := ends(first); firstRelated != related {
+			v.errorf(e.path, "%s", conflict(e, first))
+		}
+	}
+}
+
+// reports every edge of edges whose swapped edge is not in
+// others, with the message given by missing
+func (v *validator) checkMirrored(edges []edge, others []edge, missing func(e edge) string) {
+	listed := make(map[[2]string]bool, len(others))
+	for _, e := range others {
+		listed[[2]string{e.from, e.to}] = true
+	}
+
+	for _, e := range edges {
+		if !listed[[2]string{e.to, e.from}] {
+			v.errorf(e.path, "%s", missing(e))
+		}
+	}
+}
+
+// reports the cycles formed by edges, the instances of
+// acyclic relation rel, and the swapped edges of its inverse.
+// Edges from an instance to itself are reported by checkEdge.
+func (v *validator) checkCycles(rel *concept.Relation, edges []edge, inverse []edge) {
+	// the edges leaving each instance, in the order the
+	// instances first appear
+	out := make(map[string][]edge)
+	order := make([]string, 0)
+	add := func(e edge) {
+		if e.from == e.to {
+			return
+		}
+		if _, ok := out[e.from]; !ok {
+			order = append(order, e.from)
+		}
+		out[e.from] = append(out[e.from], e)
+	}
+	for _, e := range edges {
+		add(e)
+	}
+	for _, e := range inverse {
+		add(edge{rel: e.rel, from: e.to, to: e.from, path: e.path})
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	trail := make([]edge, 0) // the edges followed to reach the instance being visited
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		for _, e := range out[node] {
+			switch state[e.to] {
+			case visiting:
+				start := 0
+				for i, followed := range trail {
+					if followed.from == e.to {
+						start = i
+						break
+					}
+				}
+
+				cycle := append(append([]edge(nil), trail[start:]...), e)
+				paths := make([]string, len(cycle))
+				for i, followed := range cycle {
+					paths[i] = followed.path
+				}
+				v.errorf(cycle[0].path, "relation `%s` is acyclic, but the edges at %s form a cycle", rel, strings.Join(paths, ", "))
+
+			case unvisited:
+				trail = append(trail, e)
+				visit(e.to)
+				trail = trail[:len(trail)-1]
+			}
+		}
+		state[node] = visited
+	}
+
+	for _, node := range order {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+}
+
+// returns the JSON encoding of a value, which identifies it
+func key(data interface{}) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprintf("%#v", data)
+	}
+	return string(encoded)
+}
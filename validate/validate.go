@@ -0,0 +1,387 @@
+// Package validate checks instance data against the concepts
+// of a resolved concept tree, including the constraints set
+// by the @min, @max, @length and @pattern annotations of
+// their fields and by the properties of relations. Data is
+// expected in the shape produced by encoding/json: objects are
+// map[string]interface{}, arrays are []interface{} and
+// numbers are float64 or json.Number.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/riyanshkarani011235/meme/concept"
+)
+
+// Error is a value of an instance that does not match its type.
+type Error struct {
+	Path    string // where the value is in the instance, e.g. items[2].isDone
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Errors is the list of errors found in an instance, in
+// the order of the fields of the concepts, followed by the
+// errors in the edges of relations.
+type Errors []*Error
+
+func (errors Errors) Error() string {
+	s := make([]string, len(errors))
+	for i, e := range errors {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Instance checks that data is an instance of concept c and
+// returns a copy of it in which every missing optional field
+// that has a default value is set to that value. All errors
+// found are returned as Errors.
+func Instance(c *concept.Concept, data interface{}) (interface{}, error) {
+	return Value(&concept.ConceptType{Concept: c}, data)
+}
+
+// Value checks that data is a value of type t, like Instance.
+func Value(t concept.Type, data interface{}) (interface{}, error) {
+	v := &validator{}
+	result := v.check(t, data, "")
+	v.checkRelations()
+	if len(v.errors) > 0 {
+		return result, v.errors
+	}
+	return result, nil
+}
+
+type validator struct {
+	errors   Errors
+	patterns map[string]*regexp.Regexp // compiled @pattern arguments
+	edges    []edge                    // the instances of relations found so far
+}
+
+func (v *validator) errorf(path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// checks that data is a value of type t and returns it with
+// the defaults of missing fields filled in
+func (v *validator) check(t concept.Type, data interface{}, path string) interface{} {
+	switch t := t.(type) {
+	case *concept.PrimitiveType:
+		if !isPrimitive(t.Kind, data) {
+			v.errorf(path, "expected %s, found %s", t, describe(data))
+		}
+		return data
+
+	case *concept.ListType:
+		list, ok := data.([]interface{})
+		if !ok {
+			v.errorf(path, "expected a list, found %s", describe(data))
+			return data
+		}
+
+		result := make([]interface{}, len(list))
+		for i, elem := range list {
+			result[i] = v.check(t.Elem, elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return result
+
+	case *concept.TupleType:
+		tuple, ok := data.([]interface{})
+		if !ok || len(tuple) != len(t.Elems) {
+			v.errorf(path, "expected a tuple of %d elements, found %s", len(t.Elems), describe(data))
+			return data
+		}
+
+		result := make([]interface{}, len(tuple))
+		for i, elem := range tuple {
+			result[i] = v.check(t.Elems[i], elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return result
+
+	case *concept.OneOfType:
+		return v.checkOptions(t, t.Options, data, path)
+
+	case *concept.AnyOfType:
+		return v.checkOptions(t, t.Options, data, path)
+
+	case *concept.ConceptType:
+		return v.checkConcept(t, data, path)
+
+	case *concept.EnumType:
+		if !isMember(t.Enum, data) {
+			v.errorf(path, "expected a value of enum `%s` (%s), found %s", t, memberValues(t.Enum), describe(data))
+		}
+		return data
+	}
+
+	// type parameters of uninstantiated generic concepts
+	// accept any value
+	return data
+}
+
+// checks that data is a value of one of the options and
+// returns it as a value of the first such option
+func (v *validator) checkOptions(t concept.Type, options []concept.Type, data interface{}, path string) interface{} {
+	for _, option := range options {
+		sub := &validator{patterns: v.patterns}
+		if result := sub.check(option, data, path); len(sub.errors) == 0 {
+			v.patterns = sub.patterns
+			v.edges = append(v.edges, sub.edges...)
+			return result
+		}
+	}
+
+	v.errorf(path, "expected a value of type `%s`, found %s", t, describe(data))
+	return data
+}
+
+// checks that data is an object with the fields of the
+// concept and returns it with the defaults of missing
+// fields filled in
+func (v *validator) checkConcept(t *concept.ConceptType, data interface{}, path string) interface{} {
+	if t.Concept.Parent() == nil {
+		// every value is a Concept
+		return data
+	}
+
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		v.errorf(path, "expected an instance of `%s`, found %s", t, describe(data))
+		return data
+	}
+
+	result := make(map[string]interface{}, len(object))
+	known := make(map[string]bool)
+	for _, f := range t.Fields() {
+		known[f.Name()] = true
+		fieldPath := f.Name()
+		if path != "" {
+			fieldPath = path + "." + f.Name()
+		}
+
+		value, present := object[f.Name()]
+		switch {
+		case present:
+			errors := len(v.errors)
+			result[f.Name()] = v.check(f.Type(), value, fieldPath)
+			if len(v.errors) == errors {
+				// only values of the right type are checked
+				// against the constraints
+				v.checkConstraints(f, value, fieldPath)
+			}
+		case f.HasDefault():
+			result[f.Name()] = jsonValue(f.Default())
+		case f.Required():
+			v.errorf(fieldPath, "missing required field of `%s`", t)
+		}
+	}
+
+	unknown := make([]string, 0)
+	for name := range object {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		v.errorf(fieldPath, "`%s` has no field `%s`", t, name)
+		result[name] = object[name]
+	}
+
+	if rel := t.Concept.Relation(); rel != nil {
+		v.checkEdge(rel, result, path)
+	}
+	return result
+}
+
+// checks data, a value of the type of field f, against the
+// constraints set by the annotations of f
+func (v *validator) checkConstraints(f *concept.Field, data interface{}, path string) {
+	if a := f.Annotation("min"); a != nil {
+		if n, ok := integer(data); ok && n < a.Args[0].(int64) {
+			v.errorf(path, "%d is less than the minimum %d", n, a.Args[0])
+		}
+	}
+
+	if a := f.Annotation("max"); a != nil {
+		if n, ok := integer(data); ok && n > a.Args[0].(int64) {
+			v.errorf(path, "%d is greater than the maximum %d", n, a.Args[0])
+		}
+	}
+
+	if a := f.Annotation("length"); a != nil {
+		n := -1
+		switch data := data.(type) {
+		case string:
+			n = utf8.RuneCountInString(data)
+		case []interface{}:
+			n = len(data)
+		}
+
+		min, max := a.Args[0].(int64), a.Args[len(a.Args)-1].(int64)
+		switch {
+		case n < 0:
+			// not a string or a list
+		case len(a.Args) == 1 && int64(n) != min:
+			v.errorf(path, "expected a length of %d, found %d", min, n)
+		case int64(n) < min || int64(n) > max:
+			v.errorf(path, "expected a length between %d and %d, found %d", min, max, n)
+		}
+	}
+
+	if a := f.Annotation("pattern"); a != nil {
+		if s, ok := data.(string); ok && !v.pattern(a.Args[0].(string)).MatchString(s) {
+			v.errorf(path, "string %q does not match the pattern %q", s, a.Args[0])
+		}
+	}
+}
+
+// reports whether data is the value of a member of enum e.
+// Enums are closed, no other value is accepted.
+func isMember(e *concept.Enum, data interface{}) bool {
+	for _, m := range e.Members() {
+		switch value := m.Value().(type) {
+		case string:
+			if s, ok := data.(string); ok && s == value {
+				return true
+			}
+		case int64:
+			if n, ok := integer(data); ok && n == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns the values of the members of e, separated by commas
+func memberValues(e *concept.Enum) string {
+	values := make([]string, len(e.Members()))
+	for i, m := range e.Members() {
+		values[i] = concept.FormatValue(m.Value())
+	}
+	return strings.Join(values, ", ")
+}
+
+// returns the compiled pattern. Patterns are checked by the
+// resolver, so they always compile.
+func (v *validator) pattern(expr string) *regexp.Regexp {
+	if v.patterns == nil {
+		v.patterns = make(map[string]*regexp.Regexp)
+	}
+	if re, ok := v.patterns[expr]; ok {
+		return re
+	}
+
+	re := regexp.MustCompile(expr)
+	v.patterns[expr] = re
+	return re
+}
+
+// returns data as an integer, if it is one. Numbers with a
+// fractional part or outside of the range of int64 are not.
+func integer(data interface{}) (int64, bool) {
+	switch n := data.(type) {
+	case float64:
+		return floatInteger(n)
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return floatInteger(f)
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+// returns n as an int64 if it is a whole number in its range
+func floatInteger(n float64) (int64, bool) {
+	// -2^63 is exact as a float64, and so is 2^63, the first
+	// number past the largest int64
+	if n != math.Trunc(n) || n < math.MinInt64 || n >= -math.MinInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+// reports whether data is a value of the primitive kind
+func isPrimitive(kind concept.PrimitiveKind, data interface{}) bool {
+	switch kind {
+	case concept.Integer:
+		_, ok := integer(data)
+		return ok
+	case concept.String:
+		_, ok := data.(string)
+		return ok
+	case concept.Boolean:
+		_, ok := data.(bool)
+		return ok
+	}
+	return false
+}
+
+// converts a value of the concept model into the shape of
+// decoded JSON
+func jsonValue(value concept.Value) interface{} {
+	switch value := value.(type) {
+	case *concept.EnumMember:
+		return value.Value()
+	case concept.ListValue:
+		return jsonList(value)
+	case concept.TupleValue:
+		return jsonList(value)
+	default:
+		return value
+	}
+}
+
+func jsonList(list []concept.Value) []interface{} {
+	result := make([]interface{}, len(list))
+	for i, elem := range list {
+		result[i] = jsonValue(elem)
+	}
+	return result
+}
+
+// describes a value in error messages
+func describe(data interface{}) string {
+	switch data := data.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", data)
+	case bool:
+		return fmt.Sprintf("boolean %t", data)
+	case float64, json.Number, int, int64:
+		return fmt.Sprintf("number %v", data)
+	case []interface{}:
+		return fmt.Sprintf("a list of %d elements", len(data))
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
@@ -0,0 +1,309 @@
+package validate
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/riyanshkarani011235/meme/concept"
+	"github.com/riyanshkarani011235/meme/parser"
+	"github.com/riyanshkarani011235/meme/token"
+)
+
+// resolves the sources and returns the tree
+func resolve(sources ...string) *concept.ConceptTree {
+	fset := token.NewFileSet()
+	tree := concept.NewConceptTree()
+	for _, src := range sources {
+		f, err := parser.ParseSource(fset, "a.meme", src)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tree.Resolve(fset, f)).To(Succeed())
+	}
+	return tree
+}
+
+// decodes a JSON document
+func decode(doc string) interface{} {
+	var data interface{}
+	Expect(json.Unmarshal([]byte(doc), &data)).To(Succeed())
+	return data
+}
+
+var _ = Describe("Instance", func() {
+	tree := resolve(
+		`concept TypedList<T> { required elements [T] }`,
+		`concept TodoListItem {
+			required description Concept
+			optional isDone boolean = false
+			optional tags [string] = ["new"]
+			optional estimate oneof(integer, (integer, string))
+		}
+		concept TodoList extends TypedList<TodoListItem> {
+			optional owner string
+		}`,
+	)
+	todoList := tree.Lookup("TodoList")
+
+	It("Should accept an instance and fill in defaults", func() {
+		result, err := Instance(todoList, decode(`{
+			"owner": "me",
+			"elements": [
+				{"description": "write", "isDone": true, "estimate": 3},
+				{"description": {"any": "thing"}, "estimate": [2, "days"]}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(decode(`{
+			"owner": "me",
+			"elements": [
+				{"description": "write", "isDone": true, "estimate": 3, "tags": ["new"]},
+				{"description": {"any": "thing"}, "estimate": [2, "days"], "isDone": false, "tags": ["new"]}
+			]
+		}`)))
+	})
+
+	It("Should report every mismatch with its path", func() {
+		_, err := Instance(todoList, decode(`{
+			"elements": [
+				{"isDone": "yes"},
+				{"description": 1, "tags": ["a", 2], "estimate": 1.5},
+				"item"
+			],
+			"color": "red"
+		}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "elements[0].description", Message: "missing required field of `TodoListItem`"},
+			{Path: "elements[0].isDone", Message: "expected boolean, found string \"yes\""},
+			{Path: "elements[1].tags[1]", Message: "expected string, found number 2"},
+			{Path: "elements[1].estimate", Message: "expected a value of type `oneof(integer, (integer, string))`, found number 1.5"},
+			{Path: "elements[2]", Message: "expected an instance of `TodoListItem`, found string \"item\""},
+			{Path: "color", Message: "`TodoList` has no field `color`"},
+		}.Error()))
+	})
+
+	It("Should reject numbers that are not whole or do not fit in 64 bits", func() {
+		counter := resolve(`concept Counter { optional count integer }`).Lookup("Counter")
+
+		for _, doc := range []string{`{"count": 1e18}`, `{"count": -9223372036854775808}`, `{"count": 2.0}`} {
+			_, err := Instance(counter, decode(doc))
+			Expect(err).NotTo(HaveOccurred(), doc)
+		}
+		for _, doc := range []string{`{"count": 1e300}`, `{"count": 9223372036854775808}`, `{"count": -1e19}`, `{"count": 0.5}`} {
+			_, err := Instance(counter, decode(doc))
+			Expect(err).To(HaveOccurred(), doc)
+		}
+	})
+
+	It("Should check the constraints of annotated fields", func() {
+		ticket := resolve(`concept Ticket {
+			@pattern("^[A-Z]+-[0-9]+$") required key string
+			@min(0) @max(13) optional points integer
+			@length(1, 3) optional tags [string]
+			@length(2) optional code string
+		}`).Lookup("Ticket")
+
+		_, err := Instance(ticket, decode(`{"key": "MEME-1", "points": 13, "tags": ["a"], "code": "öä"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Instance(ticket, decode(`{"key": "meme-1", "points": -1, "tags": [], "code": "abc"}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "key", Message: `string "meme-1" does not match the pattern "^[A-Z]+-[0-9]+$"`},
+			{Path: "points", Message: "-1 is less than the minimum 0"},
+			{Path: "tags", Message: "expected a length between 1 and 3, found 0"},
+			{Path: "code", Message: "expected a length of 2, found 3"},
+		}.Error()))
+
+		_, err = Instance(ticket, decode(`{"key": 1, "points": 14}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "key", Message: "expected string, found number 1"},
+			{Path: "points", Message: "14 is greater than the maximum 13"},
+		}.Error()))
+	})
+
+	It("Should only accept the values of the members of enums", func() {
+		task := resolve(`enum Status { Todo, InProgress = "in-progress", Done }
+			enum Priority { Low = 1, High = 2 }
+			concept Task {
+				optional status Status = Todo
+				optional priority Priority
+			}`).Lookup("Task")
+
+		result, err := Instance(task, decode(`{"priority": 2}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(decode(`{"status": "Todo", "priority": 2}`)))
+
+		_, err = Instance(task, decode(`{"status": "InProgress", "priority": "High"}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "status", Message: `expected a value of enum ` + "`Status`" + ` ("Todo", "in-progress", "Done"), found string "InProgress"`},
+			{Path: "priority", Message: `expected a value of enum ` + "`Priority`" + ` (1, 2), found string "High"`},
+		}.Error()))
+	})
+
+	It("Should check the cardinalities of relations", func() {
+		board := resolve(`concept Issue { required key string }
+			concept User { required name string }
+			relation AssignedTo from many Issue to one User {}
+			relation Owns from one User to many Issue {}
+			concept Board {
+				optional assignments [AssignedTo]
+				optional ownership [Owns]
+			}`).Lookup("Board")
+
+		_, err := Instance(board, decode(`{
+			"assignments": [
+				{"from": {"key": "A"}, "to": {"name": "ann"}},
+				{"from": {"key": "B"}, "to": {"name": "ann"}},
+				{"from": {"key": "A"}, "to": {"name": "ann"}}
+			],
+			"ownership": [
+				{"from": {"name": "ann"}, "to": {"key": "A"}},
+				{"from": {"name": "ann"}, "to": {"key": "B"}}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Instance(board, decode(`{
+			"assignments": [
+				{"from": {"key": "A"}, "to": {"name": "ann"}},
+				{"from": {"key": "A"}, "to": {"name": "bob"}}
+			],
+			"ownership": [
+				{"from": {"name": "ann"}, "to": {"key": "A"}},
+				{"from": {"name": "bob"}, "to": {"key": "A"}}
+			]
+		}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "assignments[1]", Message: "relation `AssignedTo` is to one `User`, " +
+				`but {"key":"A"} is already related to {"name":"ann"} at assignments[0]`},
+			{Path: "ownership[1]", Message: "relation `Owns` is from one `User`, " +
+				`but {"key":"A"} is already related from {"name":"ann"} at ownership[0]`},
+		}.Error()))
+	})
+
+	It("Should reject cycles in acyclic relations", func() {
+		board := resolve(`concept Issue { required key string }
+			relation DependsOn from Issue to Issue acyclic {}
+			relation RelatedTo from Issue to Issue {}
+			concept Board {
+				optional dependencies [DependsOn]
+				optional related [RelatedTo]
+			}`).Lookup("Board")
+
+		_, err := Instance(board, decode(`{
+			"dependencies": [
+				{"from": {"key": "A"}, "to": {"key": "B"}},
+				{"from": {"key": "A"}, "to": {"key": "C"}},
+				{"from": {"key": "B"}, "to": {"key": "C"}}
+			],
+			"related": [
+				{"from": {"key": "A"}, "to": {"key": "B"}},
+				{"from": {"key": "B"}, "to": {"key": "A"}}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Instance(board, decode(`{
+			"dependencies": [
+				{"from": {"key": "A"}, "to": {"key": "B"}},
+				{"from": {"key": "C"}, "to": {"key": "A"}},
+				{"from": {"key": "D"}, "to": {"key": "D"}},
+				{"from": {"key": "B"}, "to": {"key": "C"}}
+			]
+		}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "dependencies[2]", Message: "relation `DependsOn` is acyclic, but the edge relates an instance to itself"},
+			{Path: "dependencies[0]", Message: "relation `DependsOn` is acyclic, but the edges at dependencies[0], dependencies[3], dependencies[1] form a cycle"},
+		}.Error()))
+
+		_, err = Instance(board, decode(`{
+			"dependencies": [
+				{"from": {"key": "A"}, "to": {"key": "B"}},
+				{"from": {"key": "C"}, "to": {"key": "A"}},
+				{"from": {"key": "B"}, "to": {"key": "C"}}
+			]
+		}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "dependencies[0]", Message: "relation `DependsOn` is acyclic, but the edges at dependencies[0], dependencies[2], dependencies[1] form a cycle"},
+		}.Error()))
+	})
+
+	It("Should find cycles across the whole instance", func() {
+		project := resolve(`concept Issue { required key string }
+			relation DependsOn from Issue to Issue acyclic {}
+			concept Epic { optional dependencies [DependsOn] }
+			concept Project {
+				optional dependencies [DependsOn]
+				optional epics [Epic]
+			}`).Lookup("Project")
+
+		_, err := Instance(project, decode(`{
+			"dependencies": [{"from": {"key": "A"}, "to": {"key": "B"}}],
+			"epics": [
+				{"dependencies": [{"from": {"key": "B"}, "to": {"key": "C"}}]},
+				{"dependencies": [{"from": {"key": "C"}, "to": {"key": "A"}}]}
+			]
+		}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "dependencies[0]", Message: "relation `DependsOn` is acyclic, " +
+				"but the edges at dependencies[0], epics[0].dependencies[0], epics[1].dependencies[0] form a cycle"},
+		}.Error()))
+	})
+
+	It("Should check the edges of symmetric relations and of inverses", func() {
+		board := resolve(`concept Issue { required key string }
+			relation RelatedTo from Issue to Issue symmetric {}
+			relation Blocks from Issue to Issue acyclic {}
+			relation BlockedBy from Issue to Issue inverse of Blocks {}
+			concept Board {
+				optional related [RelatedTo]
+				optional blocks [Blocks]
+				optional blockedBy [BlockedBy]
+			}`).Lookup("Board")
+
+		_, err := Instance(board, decode(`{
+			"related": [
+				{"from": {"key": "A"}, "to": {"key": "B"}},
+				{"from": {"key": "B"}, "to": {"key": "A"}},
+				{"from": {"key": "C"}, "to": {"key": "C"}}
+			],
+			"blocks": [{"from": {"key": "A"}, "to": {"key": "B"}}],
+			"blockedBy": [{"from": {"key": "B"}, "to": {"key": "A"}}]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		// an inverse that is not listed is not checked
+		_, err = Instance(board, decode(`{"blocks": [{"from": {"key": "A"}, "to": {"key": "B"}}]}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Instance(board, decode(`{
+			"related": [{"from": {"key": "A"}, "to": {"key": "B"}}],
+			"blocks": [{"from": {"key": "A"}, "to": {"key": "B"}}],
+			"blockedBy": [{"from": {"key": "C"}, "to": {"key": "A"}}, {"from": {"key": "D"}, "to": {"key": "D"}}]
+		}`))
+		Expect(err).To(MatchError(Errors{
+			{Path: "blockedBy[1]", Message: "relation `BlockedBy` is the inverse of `Blocks`, which is acyclic, but the edge relates an instance to itself"},
+			{Path: "related[0]", Message: `relation ` + "`RelatedTo`" + ` is symmetric, but there is no edge from {"key":"B"} to {"key":"A"}`},
+			{Path: "blocks[0]", Message: `relation ` + "`Blocks`" + ` is the inverse of ` + "`BlockedBy`" + `, but there is no edge of ` + "`BlockedBy`" + ` from {"key":"B"} to {"key":"A"}`},
+			{Path: "blockedBy[0]", Message: `relation ` + "`BlockedBy`" + ` is the inverse of ` + "`Blocks`" + `, but there is no edge of ` + "`Blocks`" + ` from {"key":"A"} to {"key":"C"}`},
+			{Path: "blockedBy[1]", Message: `relation ` + "`BlockedBy`" + ` is the inverse of ` + "`Blocks`" + `, but there is no edge of ` + "`Blocks`" + ` from {"key":"D"} to {"key":"D"}`},
+		}.Error()))
+
+		// the edges of the inverse count in the cycles of an
+		// acyclic relation
+		_, err = Instance(board, decode(`{
+			"blocks": [{"from": {"key": "A"}, "to": {"key": "B"}}],
+			"blockedBy": [{"from": {"key": "A"}, "to": {"key": "B"}}]
+		}`))
+		Expect(err).To(MatchError(ContainSubstring(
+			"blocks[0]: relation `Blocks` is acyclic, but the edges at blocks[0], blockedBy[0] form a cycle")))
+	})
+
+	It("Should check values against any type", func() {
+		_, err := Value(&concept.TupleType{Elems: []concept.Type{
+			&concept.PrimitiveType{Kind: concept.Integer},
+			&concept.PrimitiveType{Kind: concept.String},
+		}}, decode(`[1]`))
+		Expect(err).To(MatchError("expected a tuple of 2 elements, found a list of 1 elements"))
+	})
+})